@@ -0,0 +1,202 @@
+// This file contains the batch command, which solves a file of positions, one
+// per line, with a pool of workers. Results are written in the order of the
+// input as soon as every line before them is done, and a line that does not
+// parse, can not be solved or times out only fails itself.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"megaminx/notation"
+	"megaminx/puzzle"
+	"megaminx/solver"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// batchItem is a line of the input waiting for its result
+type batchItem struct {
+	line   int
+	text   string
+	result chan solveResult
+}
+
+// batchOptions configures how every line of a batch is solved
+type batchOptions struct {
+	method   string
+	maxNodes int
+	timeout  time.Duration // 0 means no limit
+}
+
+// solveLine parses and solves the position in text, never taking much longer than opts.timeout
+func solveLine(text string, opts batchOptions) solveResult {
+	res := solveResult{Input: text, Method: opts.method}
+	s, err := notation.ParsePosition(text)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	type solved struct {
+		stages     []solver.Stage
+		raw, moves puzzle.Sequence
+		stats      solver.Stats
+		err        error
+	}
+	start := time.Now()
+	stop := make(chan struct{})
+	done := make(chan solved, 1) // never blocks, so a solve that timed out can still finish
+	var mu sync.Mutex
+	var progress solver.Stats // stats of the search as of its last progress call, reported on a timeout
+	search := solver.SearchOptions{MaxNodes: opts.maxNodes, Stop: stop, Progress: func(st solver.Stats) {
+		mu.Lock()
+		progress = st
+		mu.Unlock()
+	}}
+	go func() {
+		var r solved
+		r.stages, r.raw, r.moves, r.stats, r.err = solvePosition(s, opts.method, search)
+		done <- r
+	}()
+	var timeout <-chan time.Time
+	if opts.timeout > 0 {
+		timer := time.NewTimer(opts.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var r solved
+	select {
+	case r = <-done:
+	case <-timeout:
+		// searches return shortly after stop is closed, but layers and phases can not be stopped, so
+		// the solve is left to finish in the background and its result dropped
+		close(stop)
+		mu.Lock()
+		r = solved{stats: progress, err: fmt.Errorf("timed out after %s", opts.timeout)}
+		mu.Unlock()
+		r.stats.Elapsed = time.Since(start)
+	}
+
+	res.Expanded, res.Generated = r.stats.Expanded, r.stats.Generated
+	res.ElapsedMS = float64(r.stats.Elapsed) / float64(time.Millisecond)
+	if r.err != nil {
+		res.Error = r.err.Error()
+		return res
+	}
+	res.Solution, res.Length, res.RawLength = r.moves.String(), len(r.moves), len(r.raw)
+	res.Stages = stageResults(r.stages)
+	return res
+}
+
+// runBatch solves every non-blank line read from r with workers goroutines and calls write with the
+// results in the order of the lines
+func runBatch(r io.Reader, workers int, opts batchOptions, write func(solveResult)) error {
+	jobs := make(chan *batchItem)
+	order := make(chan *batchItem, 2*workers) // lines handed out, oldest first
+	for i := 0; i < workers; i++ {
+		go func() {
+			for item := range jobs {
+				item.result <- solveLine(item.text, opts)
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		// a bufio.Reader rather than a Scanner, which gives up on the whole input at the first line longer
+		// than its buffer
+		br := bufio.NewReader(r)
+		line := 0
+		var err error
+		for err == nil {
+			var text string
+			text, err = br.ReadString('\n')
+			if err != nil && (err != io.EOF || text == "") {
+				break
+			}
+			line++
+			if text = strings.TrimSpace(text); text == "" {
+				continue
+			}
+			item := &batchItem{line: line, text: text, result: make(chan solveResult, 1)}
+			order <- item
+			jobs <- item
+		}
+		close(jobs)
+		close(order)
+		if err == io.EOF {
+			err = nil
+		}
+		readErr <- err
+	}()
+
+	for item := range order {
+		res := <-item.result
+		res.Line = item.line
+		write(res)
+	}
+	return <-readErr
+}
+
+func cmdBatch(args []string) error {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	method := fs.String("method", "phases", "astar, weighted, greedy, beam, uniform, ida, layers or phases")
+	maxNodes := fs.Int("max-nodes", 0, "give up on a line after expanding this many nodes, 0 means no limit")
+	timeout := fs.Duration("timeout", 0, "give up on a line after this long, 0 means no limit")
+	workers := fs.Int("workers", runtime.NumCPU(), "lines solved at the same time")
+	asJSON := fs.Bool("json", false, "write a JSON object per line")
+	fs.Parse(args)
+	if _, ok := methods[*method]; !ok {
+		return fmt.Errorf("unknown method %q", *method)
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	in := io.Reader(os.Stdin)
+	if fs.NArg() > 0 && fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	start := time.Now()
+	solved, failed, length, rawLength := 0, 0, 0, 0
+	err := runBatch(in, *workers, batchOptions{*method, *maxNodes, *timeout}, func(res solveResult) {
+		if res.Error == "" {
+			solved++
+			length += res.Length
+			rawLength += res.RawLength
+		} else {
+			failed++
+		}
+		if *asJSON {
+			b, _ := json.Marshal(res)
+			out.Write(append(b, '\n'))
+		} else if res.Error != "" {
+			fmt.Fprintf(out, "error: %s\n", res.Error)
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", res.Line, res.Error)
+		} else {
+			fmt.Fprintln(out, res.Solution)
+		}
+		out.Flush()
+	})
+	fmt.Fprintf(os.Stderr, "%d solved, %d failed in %s, %d moves in all, %d before simplification\n", solved, failed,
+		time.Since(start).Round(time.Millisecond), length, rawLength)
+	if err == nil && failed > 0 {
+		err = errNotSolved
+	}
+	return err
+}
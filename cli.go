@@ -0,0 +1,599 @@
+// This file contains the subcommands of the command line. Positions are read
+// from the arguments, or from standard input when there are none, either as a
+// state in the format of notation.FormatState or as a scramble applied to the
+// solved puzzle. Every command writes text, or JSON when given -json, and bench
+// can also write CSV.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"megaminx/notation"
+	"megaminx/puzzle"
+	"megaminx/render"
+	"megaminx/solver"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command is a subcommand of the command line
+type command struct {
+	usage string // arguments and a short description
+	run   func(args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"scramble": {"[-n turns] [-seed n] [-json]: print a random scramble and the state it leads to", cmdScramble},
+		"solve":    {"[-method m] [-max-nodes n] [-checkpoint file] [-resume file] [-json] [position]: solve a state or scramble", cmdSolve},
+		"batch":    {"[-method m] [-workers n] [-timeout d] [-max-nodes n] [-json] [file]: solve a position per line, in order", cmdBatch},
+		"apply":    {"[-state state] [-json] moves: apply moves to a state, the solved one by default", cmdApply},
+		"verify":   {"-state state [-json] moves: check that moves solve a state", cmdVerify},
+		"bench":    {"[-min k] [-max k] [-trials n] [-seed n] [-methods m,...] [-heuristic h] [-moves m] [-format f]: compare solvers on seeded random scrambles", cmdBench},
+		"render":   {"[-o file] [-scale s] [position]: draw a state or scramble as an SVG image, the solved puzzle for empty input", cmdRender},
+		"gui":      {": open the puzzle window", cmdGUI},
+	}
+}
+
+// errNotSolved is returned by verify when the moves do not solve the state, and by batch when a line
+// failed, making the exit status 1
+var errNotSolved = errors.New("not solved")
+
+// runCommand runs the command called name and returns the exit status
+func runCommand(name string, args []string) int {
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		flag.Usage()
+		return 2
+	}
+	if err := cmd.run(args); err != nil {
+		if err != errNotSolved {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// usage prints the global flags and the commands
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: %s [flags] [command [arguments]]\n\n", os.Args[0])
+	fmt.Fprintln(out, "Without a command, the puzzle window is opened.")
+	fmt.Fprintln(out, "\ncommands:")
+	for _, name := range []string{"scramble", "solve", "batch", "apply", "verify", "bench", "render", "gui"} {
+		fmt.Fprintf(out, "  %s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(out, "\nflags:")
+	flag.PrintDefaults()
+}
+
+// input returns the arguments joined by spaces, or all of standard input if there are none
+func input(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	b, err := io.ReadAll(os.Stdin)
+	return strings.TrimSpace(string(b)), err
+}
+
+// writeJSON writes v to standard output as indented JSON
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// randomScramble returns n random turns, never turning the same face twice in a row
+func randomScramble(n int, rng *rand.Rand) puzzle.Sequence {
+	turns := []int{1, -1, 2, -2}
+	var res puzzle.Sequence
+	for len(res) < n {
+		mv := puzzle.Move{Face: rng.Intn(12), Turns: turns[rng.Intn(len(turns))]}
+		if len(res) > 0 && res[len(res)-1].Face == mv.Face {
+			continue
+		}
+		res = append(res, mv)
+	}
+	return res
+}
+
+func cmdScramble(args []string) error {
+	fs := flag.NewFlagSet("scramble", flag.ExitOnError)
+	n := fs.Int("n", puzzle.ScrambleMoves, "number of turns")
+	seed := fs.Int64("seed", 0, "random seed, 0 picks one")
+	asJSON := fs.Bool("json", false, "write JSON")
+	fs.Parse(args)
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	seq := randomScramble(*n, rand.New(rand.NewSource(*seed)))
+	s := puzzle.NewState()
+	seq.Apply(&s)
+	if *asJSON {
+		return writeJSON(struct {
+			Scramble string `json:"scramble"`
+			State    string `json:"state"`
+			Seed     int64  `json:"seed"`
+		}{seq.String(), notation.FormatState(&s), *seed})
+	}
+	fmt.Println(seq)
+	fmt.Println(notation.FormatState(&s))
+	return nil
+}
+
+// methods maps the names accepted by -method to the search strategy they use. layers and phases are not
+// searches and have no strategy
+var methods = map[string]solver.Strategy{
+	"astar":    solver.AStar,
+	"weighted": solver.WeightedAStar,
+	"greedy":   solver.GreedyBestFirst,
+	"beam":     solver.BeamSearch,
+	"uniform":  solver.UniformCost,
+	"ida":      solver.IDAStar,
+	"layers":   -1,
+	"phases":   -1,
+}
+
+// solveResult is the output of solve
+type solveResult struct {
+	Line      int     `json:"line,omitempty"` // line of the input, set by batch
+	Input     string  `json:"input"`
+	Method    string  `json:"method"`
+	Solution  string  `json:"solution"`
+	Length    int     `json:"length"`
+	RawLength int     `json:"raw_length"` // length before simplification
+	Expanded  int     `json:"expanded,omitempty"`
+	Generated int     `json:"generated,omitempty"`
+	ElapsedMS float64 `json:"elapsed_ms"`
+	Error     string  `json:"error,omitempty"`
+
+	Stages []stageResult `json:"stages,omitempty"` // the stages of layers and phases, before simplification
+}
+
+// stageResult is a stage of a solution in the output of solve
+type stageResult struct {
+	Name   string `json:"name"`
+	Moves  string `json:"moves"`
+	Length int    `json:"length"`
+}
+
+// stageResults returns the output of stages
+func stageResults(stages []solver.Stage) []stageResult {
+	var res []stageResult
+	for _, st := range stages {
+		res = append(res, stageResult{st.Name, st.Moves.String(), len(st.Moves)})
+	}
+	return res
+}
+
+// solvePosition solves s with the named method and returns the solution found and the same solution
+// simplified, along with the stages of the solution for layers and phases. Searches use opts with the
+// strategy of the method, layers and phases ignore opts
+func solvePosition(s puzzle.State, method string, opts solver.SearchOptions) (stages []solver.Stage, raw, moves puzzle.Sequence, stats solver.Stats, err error) {
+	start := time.Now()
+	if _, err := s.Pieces(); err != nil {
+		return nil, nil, nil, solver.Stats{}, err
+	}
+	strategy, ok := methods[method]
+	if !ok {
+		return nil, nil, nil, solver.Stats{}, fmt.Errorf("unknown method %q", method)
+	}
+
+	switch method {
+	case "layers":
+		stages, err = solver.SolveLayers(s)
+	case "phases":
+		stages, err = solver.SolvePhases(s)
+	default:
+		opts.Strategy = strategy
+		node, stats, found := solver.SearchCached(s, opts)
+		raw, moves, stats, err = searchResult(node, stats, found)
+		return nil, raw, moves, stats, err
+	}
+	if err != nil {
+		return nil, nil, nil, solver.Stats{}, err
+	}
+	raw = solver.JoinStages(stages)
+	moves = solver.Simplify(raw)
+	return stages, raw, moves, solver.Stats{Depth: len(moves), Elapsed: time.Since(start)}, nil
+}
+
+// resumePosition carries on the search checkpointed in path with the named method, which must be a search
+// that can be checkpointed, and returns the position it solves along with the solutions
+func resumePosition(path, method string, opts solver.SearchOptions) (s puzzle.State, raw, moves puzzle.Sequence, stats solver.Stats, err error) {
+	strategy, ok := methods[method]
+	if !ok || strategy < 0 {
+		return s, nil, nil, stats, fmt.Errorf("-resume needs a search method, not %q", method)
+	}
+	opts.Strategy = strategy
+	node, stats, found, err := solver.ResumeSearch(path, opts)
+	if err != nil {
+		return s, nil, nil, stats, err
+	}
+	raw, moves, stats, err = searchResult(node, stats, found)
+	s = puzzle.NewState()
+	raw.Inverse().Apply(&s)
+	return s, raw, moves, stats, err
+}
+
+// searchResult returns the solution of a search and the same solution simplified, or why there is none
+func searchResult(node solver.Node, stats solver.Stats, found bool) (raw, moves puzzle.Sequence, _ solver.Stats, err error) {
+	switch {
+	case stats.Stopped:
+		return nil, nil, stats, fmt.Errorf("stopped after expanding %d nodes", stats.Expanded)
+	case !found:
+		return nil, nil, stats, fmt.Errorf("no solution found after expanding %d nodes", stats.Expanded)
+	}
+	raw = solver.Path(node)
+	return raw, solver.Simplify(raw), stats, nil
+}
+
+// stopOnInterrupt returns a channel closed when the process is interrupted, so a search stops and writes a
+// final checkpoint rather than being killed, and a function that puts interrupts back to killing it
+func stopOnInterrupt() (<-chan struct{}, func()) {
+	stop, done := make(chan struct{}), make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			close(stop)
+		case <-done:
+		}
+	}()
+	return stop, func() {
+		signal.Stop(interrupt)
+		close(done)
+	}
+}
+
+func cmdSolve(args []string) error {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	method := fs.String("method", "phases", "astar, weighted, greedy, beam, uniform, ida, layers or phases")
+	maxNodes := fs.Int("max-nodes", 0, "give up after expanding this many nodes, 0 means no limit")
+	checkpoint := fs.String("checkpoint", "", "write checkpoints of the search to this file, and a last one when interrupted")
+	resume := fs.String("resume", "", "carry on the search checkpointed in this file instead of solving a position")
+	asJSON := fs.Bool("json", false, "write JSON")
+	fs.Parse(args)
+
+	stop, restore := stopOnInterrupt()
+	defer restore()
+	opts := solver.SearchOptions{MaxNodes: *maxNodes, Checkpoint: *checkpoint, Stop: stop}
+	var text string
+	var stages []solver.Stage
+	var raw, moves puzzle.Sequence
+	var stats solver.Stats
+	var err error
+	if *resume != "" {
+		if opts.Checkpoint == "" {
+			opts.Checkpoint = *resume // so the search can be interrupted and resumed again
+		}
+		var s puzzle.State
+		s, raw, moves, stats, err = resumePosition(*resume, *method, opts)
+		if err == nil {
+			text = notation.FormatState(&s)
+		}
+	} else {
+		if *checkpoint != "" && methods[*method] < 0 {
+			return fmt.Errorf("-checkpoint needs a search method, not %q", *method)
+		}
+		if text, err = input(fs.Args()); err != nil {
+			return err
+		}
+		var s puzzle.State
+		if s, err = notation.ParsePosition(text); err != nil {
+			return err
+		}
+		stages, raw, moves, stats, err = solvePosition(s, *method, opts)
+	}
+	if stats.Err != nil {
+		fmt.Fprintln(os.Stderr, "checkpoint:", stats.Err)
+	} else if stats.Stopped && opts.Checkpoint != "" {
+		fmt.Fprintf(os.Stderr, "checkpoint written to %s, carry on with -method %s -resume %s\n", opts.Checkpoint, *method, opts.Checkpoint)
+	}
+	if *asJSON {
+		res := solveResult{
+			Input:     text,
+			Method:    *method,
+			Solution:  moves.String(),
+			Length:    len(moves),
+			RawLength: len(raw),
+			Expanded:  stats.Expanded,
+			Generated: stats.Generated,
+			ElapsedMS: float64(stats.Elapsed) / float64(time.Millisecond),
+			Stages:    stageResults(stages),
+		}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		if jsonErr := writeJSON(res); jsonErr != nil {
+			return jsonErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	for _, st := range stages {
+		fmt.Fprintf(os.Stderr, "%s (%d): %s\n", st.Name, len(st.Moves), st.Moves)
+	}
+	fmt.Println(moves)
+	fmt.Fprintf(os.Stderr, "%d moves, %d before simplification, %s\n", len(moves), len(raw), stats.Elapsed)
+	return nil
+}
+
+// stateAndMoves parses the -state flag, the solved state if it is empty, and the moves in args
+func stateAndMoves(stateText string, args []string) (puzzle.State, puzzle.Sequence, error) {
+	s := puzzle.NewState()
+	if stateText != "" {
+		var err error
+		if s, err = notation.ParsePosition(stateText); err != nil {
+			return s, nil, err
+		}
+	}
+	text, err := input(args)
+	if err != nil {
+		return s, nil, err
+	}
+	seq, err := notation.ParseMoves(text)
+	return s, seq, err
+}
+
+func cmdApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	stateText := fs.String("state", "", "state or scramble to start from, solved by default")
+	asJSON := fs.Bool("json", false, "write JSON")
+	fs.Parse(args)
+
+	s, seq, err := stateAndMoves(*stateText, fs.Args())
+	if err != nil {
+		return err
+	}
+	if err := seq.Apply(&s); err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(struct {
+			State  string `json:"state"`
+			Solved bool   `json:"solved"`
+		}{notation.FormatState(&s), s == puzzle.NewState()})
+	}
+	fmt.Println(notation.FormatState(&s))
+	return nil
+}
+
+func cmdVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	stateText := fs.String("state", "", "state or scramble the moves should solve")
+	asJSON := fs.Bool("json", false, "write JSON")
+	fs.Parse(args)
+	if *stateText == "" {
+		return errors.New("-state is required")
+	}
+
+	s, seq, err := stateAndMoves(*stateText, fs.Args())
+	if err != nil {
+		return err
+	}
+	if err := seq.Apply(&s); err != nil {
+		return err
+	}
+	solved := s == puzzle.NewState()
+	if *asJSON {
+		if err := writeJSON(struct {
+			Solved bool   `json:"solved"`
+			Length int    `json:"length"`
+			State  string `json:"state"`
+		}{solved, len(seq), notation.FormatState(&s)}); err != nil {
+			return err
+		}
+	} else if solved {
+		fmt.Println("solved")
+	} else {
+		fmt.Println("not solved")
+	}
+	if !solved {
+		return errNotSolved
+	}
+	return nil
+}
+
+// benchConfig is the configuration of a bench run, written with its JSON output
+type benchConfig struct {
+	Min       int    `json:"min"`
+	Max       int    `json:"max"`
+	Trials    int    `json:"trials"`
+	Seed      int64  `json:"seed"`
+	Methods   string `json:"methods"`
+	Heuristic string `json:"heuristic"`
+	Moves     string `json:"moves"`
+	MaxNodes  int    `json:"max_nodes"`
+}
+
+// benchSolvers returns the solvers of the comma separated methods in cfg. Searches use the heuristic and
+// move set of cfg, layers and phases ignore them
+func benchSolvers(cfg benchConfig) ([]solver.BenchSolver, error) {
+	opts := solver.SearchOptions{MaxNodes: cfg.MaxNodes}
+	switch cfg.Heuristic {
+	case "default":
+	case "stickers":
+		opts.Heuristic = solver.H
+	case "tables":
+		t := solver.CurrentTables()
+		if t == nil {
+			return nil, errors.New("no pruning tables loaded, build them with -build-tables")
+		}
+		opts.Heuristic = t.Heuristic
+	default:
+		return nil, fmt.Errorf("unknown heuristic %q", cfg.Heuristic)
+	}
+	var err error
+	if opts.Moves, err = solver.ParseMoveSet(cfg.Moves); err != nil {
+		return nil, err
+	}
+
+	var res []solver.BenchSolver
+	for _, name := range strings.Split(cfg.Methods, ",") {
+		st, ok := methods[name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("unknown method %q", name)
+		case st < 0:
+			name := name
+			res = append(res, solver.BenchSolver{Name: name, Solve: func(s puzzle.State) (puzzle.Sequence, solver.Stats, error) {
+				_, _, moves, stats, err := solvePosition(s, name, solver.SearchOptions{})
+				return moves, stats, err
+			}})
+		default:
+			o := opts
+			o.Strategy = st
+			res = append(res, solver.SearchSolver(name, o))
+		}
+	}
+	return res, nil
+}
+
+// benchCSVHeader is the header of the trial records written by bench -format csv
+var benchCSVHeader = []string{"depth", "trial", "seed", "solver", "scramble", "solved", "length",
+	"expanded", "generated", "max_frontier", "elapsed_ms", "alloc_bytes", "error"}
+
+// benchCSVRecord returns the fields of t in the order of benchCSVHeader
+func benchCSVRecord(t solver.BenchTrial) []string {
+	return []string{
+		strconv.Itoa(t.Depth),
+		strconv.Itoa(t.Trial),
+		strconv.FormatInt(t.Seed, 10),
+		t.Solver,
+		t.Scramble,
+		strconv.FormatBool(t.Solved),
+		strconv.Itoa(t.Length),
+		strconv.Itoa(t.Expanded),
+		strconv.Itoa(t.Generated),
+		strconv.Itoa(t.MaxFrontier),
+		strconv.FormatFloat(t.ElapsedMS, 'f', 3, 64),
+		strconv.FormatUint(t.AllocBytes, 10),
+		t.Error,
+	}
+}
+
+// writeBenchSummary writes a table of the mean, median and 95th percentile of each summary
+func writeBenchSummary(w io.Writer, cfg benchConfig, summaries []solver.BenchSummary) {
+	fmt.Fprintf(w, "seed %d, %d trials per depth, heuristic %s, moves %s\n", cfg.Seed, cfg.Trials, cfg.Heuristic, cfg.Moves)
+	fmt.Fprintf(w, "%5s %-10s %6s %26s %26s %20s %20s\n", "depth", "solver", "solved",
+		"time ms mean/median/p95", "expanded mean/median/p95", "KB mean/median/p95", "length mean/median/p95")
+	for _, r := range summaries {
+		fmt.Fprintf(w, "%5d %-10s %3d/%-2d %26s %26s %20s %20s\n", r.Depth, r.Solver, r.Solved, r.Trials,
+			formatSummary(r.ElapsedMS, 1, 2), formatSummary(r.Expanded, 1, 0),
+			formatSummary(r.AllocBytes, 1024, 0), formatSummary(r.Length, 1, 1))
+	}
+}
+
+// formatSummary formats the values of sum divided by unit with prec decimals
+func formatSummary(sum solver.Summary, unit float64, prec int) string {
+	return fmt.Sprintf("%.*f/%.*f/%.*f", prec, sum.Mean/unit, prec, sum.Median/unit, prec, sum.P95/unit)
+}
+
+func cmdBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var cfg benchConfig
+	fs.IntVar(&cfg.Min, "min", 3, "smallest number of scramble turns")
+	fs.IntVar(&cfg.Max, "max", 14, "largest number of scramble turns")
+	fs.IntVar(&cfg.Trials, "trials", 5, "scrambles per number of turns")
+	fs.Int64Var(&cfg.Seed, "seed", 0, "random seed of the scrambles, 0 picks one")
+	fs.StringVar(&cfg.Methods, "methods", "astar", "comma separated methods, see solve")
+	fs.StringVar(&cfg.Heuristic, "heuristic", "default", "heuristic of the searches: default, stickers or tables")
+	fs.StringVar(&cfg.Moves, "moves", "ccw", "turns the searches make: ccw, fifths or face")
+	fs.IntVar(&cfg.MaxNodes, "max-nodes", 0, "give up after expanding this many nodes, 0 means no limit")
+	format := fs.String("format", "text", "text for a summary table, csv for trial records with the summary on standard error, or json for both")
+	asJSON := fs.Bool("json", false, "write JSON, the same as -format json")
+	fs.Parse(args)
+	if *asJSON {
+		*format = "json"
+	}
+	if *format != "text" && *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if cfg.Trials < 1 || cfg.Min < 0 || cfg.Max < cfg.Min {
+		return errors.New("need -trials of at least 1 and 0 <= -min <= -max")
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	solvers, err := benchSolvers(cfg)
+	if err != nil {
+		return err
+	}
+
+	run := solver.BenchConfig{MinDepth: cfg.Min, MaxDepth: cfg.Max, Trials: cfg.Trials, Seed: cfg.Seed}
+	switch *format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write(benchCSVHeader)
+		summaries := solver.RunBenchmark(run, solvers, func(t solver.BenchTrial) {
+			w.Write(benchCSVRecord(t))
+			w.Flush()
+		})
+		writeBenchSummary(os.Stderr, cfg, summaries)
+		return w.Error()
+	case "json":
+		trials := []solver.BenchTrial{}
+		summaries := solver.RunBenchmark(run, solvers, func(t solver.BenchTrial) {
+			trials = append(trials, t)
+		})
+		return writeJSON(struct {
+			Config  benchConfig           `json:"config"`
+			Trials  []solver.BenchTrial   `json:"trials"`
+			Summary []solver.BenchSummary `json:"summary"`
+		}{cfg, trials, summaries})
+	}
+	writeBenchSummary(os.Stdout, cfg, solver.RunBenchmark(run, solvers, nil))
+	return nil
+}
+
+func cmdRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "", "file to write, standard output by default")
+	scale := fs.Float64("scale", 18, "size of a face in pixels")
+	fs.Parse(args)
+
+	text, err := input(fs.Args())
+	if err != nil {
+		return err
+	}
+	s := puzzle.NewState()
+	if text != "" {
+		if s, err = notation.ParsePosition(text); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		return render.SVG(os.Stdout, s, *scale)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := render.SVG(f, s, *scale); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func cmdGUI(args []string) error {
+	return runGUI()
+}
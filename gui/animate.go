@@ -0,0 +1,130 @@
+//go:build !headless
+
+// This file animates face turns. While a turn is shown, the puzzle is drawn
+// as it was before the turn with every sticker the turn moves lifted out of
+// its place: the stickers of the turning face rotate about its center, and the
+// rows of the neighboring faces glide to the places they end up in.
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"math"
+	"megaminx/puzzle"
+	"time"
+)
+
+// turnAnimation is a turn being shown, from the state before it
+type turnAnimation struct {
+	from    puzzle.State
+	move    puzzle.Move
+	started time.Time
+}
+
+// progress returns how far a turn taking d has come, eased in and out, from 0 to 1
+func (a *turnAnimation) progress(d time.Duration) float64 {
+	if d <= 0 {
+		return 1
+	}
+	t := float64(time.Since(a.started)) / float64(d)
+	if t >= 1 {
+		return 1
+	}
+	return t * t * (3 - 2*t)
+}
+
+// stickerCenter returns the center of tile of the face drawn with vs, as returned by netVertices
+func stickerCenter(vs []ebiten.Vertex, tile int) (float32, float32) {
+	var x, y float32
+	for _, v := range vs[6+5*tile : 11+5*tile] {
+		x += v.DstX
+		y += v.DstY
+	}
+	return x / 5, y / 5
+}
+
+// faceCenter returns the center of the face drawn with vs
+func faceCenter(vs []ebiten.Vertex) (float32, float32) {
+	var x, y float32
+	for tile := 0; tile < 10; tile++ {
+		tx, ty := stickerCenter(vs, tile)
+		x += tx
+		y += ty
+	}
+	return x / 10, y / 10
+}
+
+// stickerIndices returns the indices of is, as returned by netVertices, filling tile, counted from the
+// first vertex of the tile
+func stickerIndices(is []uint16, tile int) []uint16 {
+	first := uint16(6 + 5*tile)
+	var res []uint16
+	for i := 0; i+2 < len(is); i += 3 {
+		if is[i] >= first && is[i] < first+5 {
+			res = append(res, is[i]-first, is[i+1]-first, is[i+2]-first)
+		}
+	}
+	return res
+}
+
+// drawTurn draws a, p of the way through the turn
+func drawTurn(screen *ebiten.Image, a *turnAnimation, v view, p float64) {
+	faces, is := netVertices(v)
+	for face, vs := range faces {
+		PaintFace(vs, &a.from, face)
+	}
+	var tileIs [10][]uint16
+	for tile := range tileIs {
+		tileIs[tile] = stickerIndices(is, tile)
+	}
+
+	// lift every sticker the turn moves out of its place, then leave a hole there
+	sources := a.move.Sources()
+	var moving []ebiten.Vertex
+	var movingIs []uint16
+	for face := range faces {
+		for tile := 0; tile < 10; tile++ {
+			src := int(sources[face][tile])
+			if src == face*10+tile {
+				continue
+			}
+			sf, st := src/10, src%10
+			sticker := make([]ebiten.Vertex, 5)
+			copy(sticker, faces[sf][6+5*st:11+5*st])
+			tx, ty := stickerCenter(faces[face], tile)
+			if sf == a.move.Face {
+				cx, cy := faceCenter(faces[sf])
+				sx, sy := stickerCenter(faces[sf], st)
+				angle := math.Atan2(float64(ty-cy), float64(tx-cx)) - math.Atan2(float64(sy-cy), float64(sx-cx))
+				angle = math.Remainder(angle, 2*math.Pi) // the short way round, as no turn is more than 2 fifths
+				translateVertices(sticker, -cx, -cy)
+				rotateVertices(sticker, angle*p)
+				translateVertices(sticker, cx, cy)
+			} else {
+				sx, sy := stickerCenter(faces[sf], st)
+				translateVertices(sticker, (tx-sx)*float32(p), (ty-sy)*float32(p))
+			}
+			for _, i := range tileIs[st] {
+				movingIs = append(movingIs, uint16(len(moving))+i)
+			}
+			moving = append(moving, sticker...)
+		}
+	}
+	for face, vs := range faces {
+		for tile := 0; tile < 10; tile++ {
+			if int(sources[face][tile]) == face*10+tile {
+				continue
+			}
+			for k := 6 + 5*tile; k < 11+5*tile; k++ {
+				vs[k].ColorR, vs[k].ColorG, vs[k].ColorB = 0.15, 0.15, 0.15
+			}
+		}
+	}
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AntiAlias = true
+	for _, vs := range faces {
+		screen.DrawTriangles(vs, is, whiteImage, op)
+	}
+	screen.DrawTriangles(moving, movingIs, whiteImage, op)
+}
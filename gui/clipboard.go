@@ -0,0 +1,67 @@
+//go:build !headless
+
+// This file reads the clipboard for pasting into the move entry overlay.
+// Ebitengine has no clipboard API, so the text comes from the command each
+// system has for printing the clipboard: pbpaste on macOS, Get-Clipboard in
+// PowerShell on Windows, and wl-paste, xclip or xsel elsewhere, whichever is
+// installed. The command runs in the background so the window keeps drawing.
+
+package gui
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommands lists the commands that print the clipboard on this system, tried in order
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-NonInteractive", "-Command", "Get-Clipboard -Raw"}}
+	default:
+		return [][]string{
+			{"wl-paste", "--no-newline"},
+			{"xclip", "-selection", "clipboard", "-out"},
+			{"xsel", "--clipboard", "--output"},
+		}
+	}
+}
+
+// readClipboard returns the text on the clipboard, using the first command of clipboardCommands installed
+func readClipboard() (string, error) {
+	var names []string
+	for _, args := range clipboardCommands() {
+		names = append(names, args[0])
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		hideWindow(cmd)
+		out, err := cmd.Output()
+		if err != nil {
+			return "", errors.New("reading the clipboard: " + err.Error())
+		}
+		return string(out), nil
+	}
+	return "", errors.New("can not read the clipboard without " + strings.Join(names, ", ") + ", drop a text file instead")
+}
+
+// clipboardResult is the text read by pasteClipboard
+type clipboardResult struct {
+	text string
+	err  error
+}
+
+// pasteClipboard reads the clipboard in the background, sending the text on the channel returned
+func pasteClipboard() <-chan clipboardResult {
+	res := make(chan clipboardResult, 1)
+	go func() {
+		text, err := readClipboard()
+		res <- clipboardResult{text, err}
+	}()
+	return res
+}
@@ -0,0 +1,8 @@
+//go:build !headless && !windows
+
+package gui
+
+import "os/exec"
+
+// hideWindow does nothing where commands open no window
+func hideWindow(cmd *exec.Cmd) {}
@@ -0,0 +1,13 @@
+//go:build !headless
+
+package gui
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow keeps the console window of cmd from flashing up over the puzzle
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
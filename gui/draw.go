@@ -0,0 +1,192 @@
+//go:build !headless
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"math"
+	"megaminx/puzzle"
+	"megaminx/render"
+)
+
+// rotateVertices rotates the vertices in vs with respect to the origin
+// by r radians. Equivalent to multiplication with rotation matrix.
+func rotateVertices(vs []ebiten.Vertex, r float64) {
+	for i := range vs {
+		x := vs[i].DstX
+		y := vs[i].DstY
+		vs[i].DstX = x*float32(math.Cos(r)) - y*float32(math.Sin(r))
+		vs[i].DstY = x*float32(math.Sin(r)) + y*float32(math.Cos(r))
+	}
+}
+
+// scaleVertices scales the vertices in vs by the specified scale factor
+func scaleVertices(vs []ebiten.Vertex, scale float32) {
+	for i := range vs {
+		vs[i].DstX *= scale
+		vs[i].DstY *= scale
+	}
+}
+
+func translateVertices(vs []ebiten.Vertex, x, y float32) {
+	for i := range vs {
+		vs[i].DstX += x
+		vs[i].DstY += y
+	}
+}
+
+func reflectVerticesOverY(vs []ebiten.Vertex) {
+	for i := range vs {
+		vs[i].DstX = -vs[i].DstX
+	}
+}
+
+// drawSelectors draws the face selectors and returns their vertices, which Update hit-tests clicks against
+func drawSelectors(screen *ebiten.Image) [][]ebiten.Vertex {
+	selectors := make([][]ebiten.Vertex, 12)
+	var path vector.Path
+
+	path.MoveTo(0, 0)
+	path.LineTo(20, 0)
+	path.LineTo(20, 20)
+	path.LineTo(0, 20)
+	path.Close()
+
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	translateVertices(vs, 80, 10)
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AntiAlias = true
+
+	for i := 0; i < 12; i++ {
+		newVs := make([]ebiten.Vertex, len(vs))
+		copy(newVs, vs)
+		translateVertices(newVs, float32(i*20), 0)
+		for j := range newVs {
+			newVs[j].ColorR = float32(render.Color(i).R) / 255
+			newVs[j].ColorG = float32(render.Color(i).G) / 255
+			newVs[j].ColorB = float32(render.Color(i).B) / 255
+		}
+		selectors[i] = newVs
+		screen.DrawTriangles(newVs, is, whiteImage, op)
+		translateVertices(vs, 5, 0)
+	}
+	return selectors
+}
+
+func drawMarker(screen *ebiten.Image, selectors [][]ebiten.Vertex, s int) {
+	var path vector.Path
+
+	path.MoveTo(selectors[s][0].DstX, selectors[s][2].DstY+5)
+	path.LineTo(selectors[s][1].DstX, selectors[s][2].DstY+5)
+	path.LineTo(selectors[s][1].DstX, selectors[s][2].DstY+10)
+	path.LineTo(selectors[s][0].DstX, selectors[s][2].DstY+10)
+	path.Close()
+
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+
+	for i := range vs {
+		vs[i].ColorR = 1
+		vs[i].ColorG = 1
+		vs[i].ColorB = 1
+	}
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AntiAlias = true
+	screen.DrawTriangles(vs, is, whiteImage, op)
+}
+
+// getFacePath returns the vertices and indices filling the center and stickers of a face, in the order of
+// render.FacePolygons
+func getFacePath() ([]ebiten.Vertex, []uint16) {
+	var path vector.Path
+	for _, poly := range render.FacePolygons() {
+		path.MoveTo(float32(poly[0].X), float32(poly[0].Y))
+		for _, p := range poly[1:] {
+			path.LineTo(float32(p.X), float32(p.Y))
+		}
+		path.Close()
+	}
+	return path.AppendVerticesAndIndicesForFilling(nil, nil)
+}
+
+// netFaces places the faces of each half of the net, in the order of the faces of the half: rotated by r
+// radians, reflected over the y axis before the rotation if reflectFirst and after it otherwise, and moved
+// dx and dy faces from the center of the view
+var netFaces = []struct {
+	r            float64
+	reflectFirst bool
+	dx, dy       float32
+}{
+	{0, false, 0, 0},                    // middle
+	{math.Pi, false, 0, 3.33},           // bottom
+	{math.Pi * .6, true, 3.18, 1},       // bottom right
+	{math.Pi * -0.2, false, 1.95, -2.7}, // top right
+	{math.Pi * 0.2, false, -1.95, -2.7}, // top left
+	{math.Pi * 0.6, false, -3.18, 1},    // bottom left
+}
+
+// netVertices returns the vertices of every face where drawFaces draws it in v, in the order of
+// getFacePath, and the indices filling a face. The top half of the puzzle is drawn on the right and the
+// bottom half on the left
+func netVertices(v view) ([12][]ebiten.Vertex, []uint16) {
+	vs, is := getFacePath()
+
+	scale := v.scale
+	scaleVertices(vs, scale)
+	rotateVertices(vs, math.Pi)
+
+	var res [12][]ebiten.Vertex
+	for half, shift := range []float32{6, -6} {
+		for i, place := range netFaces {
+			faceVs := make([]ebiten.Vertex, len(vs))
+			copy(faceVs, vs)
+			if place.reflectFirst {
+				reflectVerticesOverY(faceVs)
+				rotateVertices(faceVs, place.r)
+			} else {
+				rotateVertices(faceVs, place.r)
+				reflectVerticesOverY(faceVs)
+			}
+			translateVertices(faceVs, v.x, v.y)
+			translateVertices(faceVs, scale*(place.dx+shift), scale*place.dy)
+			res[half*6+i] = faceVs
+		}
+	}
+	return res, is
+}
+
+func drawFaces(screen *ebiten.Image, s *puzzle.State, v view) {
+	faces, is := netVertices(v)
+
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AntiAlias = true
+
+	for face, vs := range faces {
+		PaintFace(vs, s, face)
+		screen.DrawTriangles(vs, is, whiteImage, op)
+	}
+}
+
+// PaintFace colors the vertices of face, as returned by getFacePath, with the stickers of s
+func PaintFace(vs []ebiten.Vertex, s *puzzle.State, face int) {
+	// first six vertices of vs are painted the color of the face
+	for i := 0; i < 6; i++ {
+		vs[i].ColorR = float32(render.Color(face).R) / 255
+		vs[i].ColorG = float32(render.Color(face).G) / 255
+		vs[i].ColorB = float32(render.Color(face).B) / 255
+	}
+	i := 6 // index into vs
+
+	tiles := s[face]
+	// paint the first five vertices in vs with tiles[0], next five with tiles[1], ...
+	for j := 0; j < len(tiles); j++ {
+		for k := 0; k < 5; k++ {
+			idx := (i + (5 * j) + k) % 56
+			vs[idx].ColorR = float32(render.Color(int(tiles[j])).R) / 255
+			vs[idx].ColorG = float32(render.Color(int(tiles[j])).G) / 255
+			vs[idx].ColorB = float32(render.Color(int(tiles[j])).B) / 255
+		}
+	}
+}
@@ -0,0 +1,87 @@
+//go:build !headless
+
+// This file is the edit mode, for entering the state of a real puzzle. The
+// selectors become a palette: clicking a sticker paints it the selected color
+// and right-clicking one picks its color. The stickers are checked as they are
+// painted, and Enter solves them once they make a reachable state.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"megaminx/puzzle"
+	"megaminx/solver"
+)
+
+// updateEdit enters and leaves the edit mode with Tab, and solves the stickers entered with Enter
+func (g *Game) updateEdit() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		if g.editing {
+			g.editing = false
+		} else {
+			g.startEdit()
+		}
+	}
+	if g.editing && g.solving == nil && inpututil.IsKeyJustPressed(ebiten.KeyEnter) && checkStickers(&g.state) == nil {
+		g.editing = false
+		g.startSolve("in phases", stagesSolve(solver.SolvePhases))
+	}
+}
+
+// startEdit enters the edit mode on the puzzle shown, cancelling the running solve, whose solution would
+// replace the stickers entered
+func (g *Game) startEdit() {
+	if g.solving != nil {
+		g.cancelSolve()
+	}
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	g.setState(g.state)
+	g.editing = true
+}
+
+// paint paints tile of face the selected color, or picks its color if pick. The centers can not be painted
+func (g *Game) paint(face, tile int, pick bool) {
+	switch {
+	case tile == -1:
+	case pick:
+		g.selected = int(g.state[face][tile])
+	default:
+		g.state[face][tile] = byte(g.selected)
+	}
+}
+
+// checkStickers returns an error if s can not be reached by turning the solved puzzle. The colors are
+// counted first, as a sticker painted the wrong color is the likeliest slip
+func checkStickers(s *puzzle.State) error {
+	var counts [12]int
+	for face := range s {
+		for _, c := range s[face] {
+			if int(c) >= len(counts) {
+				return fmt.Errorf("a sticker of the %s face has no color", puzzle.ColorName(face))
+			}
+			counts[c]++
+		}
+	}
+	for c, n := range counts {
+		if n != 10 {
+			return fmt.Errorf("%d %s stickers, there should be 10 besides the center", n, puzzle.ColorName(c))
+		}
+	}
+	_, err := s.Pieces()
+	return err
+}
+
+// drawEdit draws the color being painted and whether the stickers make a reachable state, at bottom
+func (g *Game) drawEdit(screen *ebiten.Image, bottom int) {
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Painting %s: right click picks, Tab ends", puzzle.ColorName(g.selected)), 5, 40)
+	msg := "Valid: press Enter to solve"
+	if err := checkStickers(&g.state); err != nil {
+		msg = "Invalid: " + err.Error()
+	}
+	ebitenutil.DebugPrintAt(screen, msg, 5, bottom)
+}
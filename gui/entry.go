@@ -0,0 +1,164 @@
+//go:build !headless
+
+// This file is the move entry overlay, for typing a scramble or algorithm in
+// face turn or Pochmann notation. The text is parsed as it is typed, and the
+// token that does not parse is underlined. A scramble can be pasted with
+// Ctrl+V or Cmd+V, or by dropping a text file on the window.
+
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"io/fs"
+	"megaminx/notation"
+	"megaminx/puzzle"
+	"strings"
+	"unicode/utf8"
+)
+
+const charWidth = 6 // width of a character of the debug font
+
+// entry is the text typed in the move entry overlay
+type entry struct {
+	text    []rune
+	moves   puzzle.Sequence        // the text parsed
+	err     error                  // why the text does not parse, nil if it does
+	pasting <-chan clipboardResult // the clipboard being read, nil if it is not
+}
+
+// set replaces the text with text and parses it
+func (e *entry) set(text []rune) {
+	e.text = text
+	e.moves, e.err = notation.ParseMoves(string(text))
+}
+
+// updateEntry handles typing in the overlay: Enter animates the moves typed from the puzzle shown, shift
+// Enter makes them at once, both adding them to the history, Ctrl+V or Cmd+V pastes and Esc closes the
+// overlay
+func (g *Game) updateEntry() {
+	e := g.entry
+	text := ebiten.AppendInputChars(e.text)
+	if n := len(text); n > 0 && repeating(ebiten.KeyBackspace) {
+		text = text[:n-1]
+	}
+	if e.pasting == nil && inpututil.IsKeyJustPressed(ebiten.KeyV) &&
+		(ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)) {
+		e.pasting = pasteClipboard()
+	}
+	var pasteErr error
+	select {
+	case res := <-e.pasting: // never ready while pasting is nil
+		e.pasting = nil
+		if res.err != nil {
+			pasteErr = res.err
+		} else if pasted := strings.Fields(res.text); len(pasted) > 0 {
+			if len(text) > 0 && text[len(text)-1] != ' ' {
+				text = append(text, ' ')
+			}
+			text = append(text, []rune(strings.Join(pasted, " "))...)
+		}
+	default:
+	}
+	if files := ebiten.DroppedFiles(); files != nil {
+		if dropped, err := droppedText(files); err != nil {
+			pasteErr = err
+		} else {
+			text = []rune(strings.Join(strings.Fields(dropped), " "))
+		}
+	}
+	if string(text) != string(e.text) {
+		e.set(text)
+	}
+	if pasteErr != nil {
+		e.err = pasteErr
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.entry = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) && e.err == nil && len(e.moves) > 0:
+		g.entry = nil
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.makeMoves(e.moves)
+		} else {
+			g.replay(e.moves)
+		}
+	}
+}
+
+// repeating reports whether key was just pressed or has been held long enough to repeat
+func repeating(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || d >= 30 && d%3 == 0
+}
+
+// droppedText returns the text of the first file dropped on the window
+func droppedText(files fs.FS) (string, error) {
+	dir, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return "", err
+	}
+	for _, f := range dir {
+		if f.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(files, f.Name())
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return "", errors.New("no file dropped")
+}
+
+// makeMoves makes moves at once and records them in the history
+func (g *Game) makeMoves(moves puzzle.Sequence) {
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	g.player = nil
+	for _, mv := range moves {
+		g.history.record(mv)
+		mv.Apply(&g.state)
+	}
+	g.history.follow(g.historyRows())
+}
+
+// drawEntry draws the overlay over a window width pixels wide, wrapping the text and underlining the token
+// that does not parse
+func drawEntry(screen *ebiten.Image, e *entry, width int) {
+	const x, y = 20, hudTop
+	perLine := (width - 2*x - 20) / charWidth
+	text := append(append([]rune(nil), e.text...), '_')
+	lines := (len(text) + perLine - 1) / perLine
+
+	vector.DrawFilledRect(screen, x, y, float32(width-2*x), float32((lines+3)*rowHeight+10), color.RGBA{A: 224}, false)
+	ebitenutil.DebugPrintAt(screen, "Moves or Pochmann: Enter animates, shift+Enter makes at once, Ctrl+V pastes, Esc closes", x+10, y+5)
+	for i := 0; i < lines; i++ {
+		end := (i + 1) * perLine
+		if end > len(text) {
+			end = len(text)
+		}
+		ebitenutil.DebugPrintAt(screen, string(text[i*perLine:end]), x+10, y+5+(i+1)*rowHeight)
+	}
+
+	status := fmt.Sprintf("%d moves", len(e.moves))
+	var perr *notation.ParseError
+	if errors.As(e.err, &perr) {
+		start := utf8.RuneCountInString(string(e.text)[:perr.Pos])
+		for i := start; i < start+utf8.RuneCountInString(perr.Token); i++ {
+			ux, uy := x+10+i%perLine*charWidth, y+5+(i/perLine+2)*rowHeight-2
+			vector.DrawFilledRect(screen, float32(ux), float32(uy), charWidth, 2, color.RGBA{R: 0xff, A: 0xff}, false)
+		}
+		status = fmt.Sprintf("column %d: %q: %s", start+1, perr.Token, perr.Msg)
+	} else if e.err != nil {
+		status = e.err.Error()
+	}
+	ebitenutil.DebugPrintAt(screen, status, x+10, y+5+(lines+1)*rowHeight)
+}
@@ -0,0 +1,268 @@
+//go:build !headless
+
+// Package gui is the puzzle window. It is left out of headless builds, made
+// with -tags headless, as merely importing ebiten needs a display.
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"image/color"
+	"megaminx/puzzle"
+	"megaminx/render"
+	"megaminx/solver"
+	"time"
+)
+
+const rotations = 10
+
+// whiteImage is the source image of every triangle drawn, created when the window opens
+var whiteImage *ebiten.Image
+
+// Game is the puzzle window. It owns the puzzle shown, which only Update changes; other goroutines hand it
+// solutions to play through Unwind
+type Game struct {
+	TurnTime time.Duration // time a turn takes to animate, 0 turns instantly
+	Keys     Bindings      // keys turning the faces
+
+	state       puzzle.State
+	help        bool // whether the help overlay is shown
+	selected    int
+	selectors   [][]ebiten.Vertex // vertices of the face selectors as last drawn, in pixels of the HUD
+	pressed     *press            // mouse button held down on a face, nil if there is none
+	editing     bool              // whether the stickers are being painted, see edit.go
+	entry       *entry            // moves being typed, nil if the overlay is closed
+	solid       bool              // whether the puzzle is drawn in 3D rather than as a net
+	camera      render.Camera     // camera of the 3D view
+	orbit       *orbit            // drag of the camera, nil if there is none
+	hudWidth    int               // size of the window, in pixels of the HUD
+	hudHeight   int
+	deviceScale float64        // pixels of the screen per pixel of the HUD
+	hud         *ebiten.Image  // image the HUD is drawn on
+	zoom        float64        // zoom of the puzzle, 1 to fit the window
+	panX, panY  float64        // shift of the puzzle from the middle of its space, in pixels of the HUD
+	panning     *pan           // drag of the puzzle, nil if there is none
+	turning     *turnAnimation // turn being animated, not yet made on state. nil if there is none
+	player      *player        // solution being played, nil if there is none
+	history     history        // turns made by hand
+	unwind      chan solution  // solutions sent by Unwind, picked up by Update
+	solving     *solving       // solve running in the background, nil if there is none
+	message     string         // how the last solve ended, shown until the next one starts
+}
+
+// solution is a solution to play, from the state it solves
+type solution struct {
+	start puzzle.State
+	moves puzzle.Sequence
+}
+
+// NewGame returns a game showing the solved puzzle
+func NewGame() *Game {
+	return &Game{
+		TurnTime:    300 * time.Millisecond,
+		Keys:        DefaultBindings,
+		camera:      defaultCamera,
+		deviceScale: 1,
+		zoom:        1,
+		state:       puzzle.NewState(),
+		unwind:      make(chan solution),
+	}
+}
+
+func (g *Game) Update() error {
+	select {
+	case sol := <-g.unwind:
+		g.play(sol)
+	default:
+	}
+
+	if g.solving != nil {
+		g.pollSolve()
+	}
+	if g.turning != nil && g.turning.progress(g.turnTime()) >= 1 {
+		g.finishTurn()
+	}
+	if g.entry != nil { // the keys are typed into the overlay
+		g.updateEntry()
+		return nil
+	}
+
+	if g.solving != nil && inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.cancelSolve()
+	}
+	if g.player != nil {
+		g.updatePlayer()
+	}
+	g.updateHistory()
+	g.updateEdit()
+	g.updateKeys()
+	g.updateView()
+	if g.solid {
+		g.updateOrbit(g.view())
+	} else {
+		g.updateMouse(g.view())
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		g.solid, g.pressed, g.orbit = !g.solid, nil, nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeySlash) {
+		g.help = !g.help
+	}
+	if !g.editing && inpututil.IsKeyJustPressed(ebiten.KeyN) {
+		g.entry = &entry{}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) { // if left click just pressed
+		// figure out where
+		xi, yi := g.hudCursor()
+		x := float32(xi)
+		y := float32(yi)
+		for i, s := range g.selectors {
+			if x >= s[0].DstX && x <= s[1].DstX && y >= s[0].DstY && y <= s[2].DstY {
+				g.selected = i
+				break
+			}
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
+		g.turnByHand(puzzle.Move{Face: g.selected, Turns: 1})
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
+		g.turnByHand(puzzle.Move{Face: g.selected, Turns: -1})
+	}
+
+	if g.solving == nil && inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		g.scramble(rotations)
+		g.startSolve("with A*", searchSolve)
+	}
+
+	if g.solving == nil && inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.scramble(puzzle.ScrambleMoves)
+		g.startSolve("by layers", stagesSolve(solver.SolveLayers))
+	}
+
+	if g.solving == nil && inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.scramble(puzzle.ScrambleMoves)
+		g.startSolve("in phases", stagesSolve(solver.SolvePhases))
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.setState(puzzle.NewState())
+	}
+
+	return nil
+}
+
+// setState shows s, dropping the turn being animated, the solution being played and the history
+func (g *Game) setState(s puzzle.State) {
+	g.state, g.turning, g.player, g.history = s, nil, nil, history{}
+}
+
+// scramble resets the puzzle and makes turns random clockwise turns
+func (g *Game) scramble(turns int) {
+	s := puzzle.NewState()
+	s.Randomize(turns)
+	g.setState(s)
+	g.editing = false
+}
+
+// turnByHand animates mv and records it in the history, leaving the solution being played as the puzzle
+// no longer follows it. Nothing turns in the edit mode
+func (g *Game) turnByHand(mv puzzle.Move) {
+	if g.editing {
+		return // the stickers would no longer match the puzzle being entered
+	}
+	g.player = nil
+	g.history.record(mv)
+	g.history.follow(g.historyRows())
+	g.animate(mv)
+}
+
+// animate starts animating mv, first making the turn being animated, if any
+func (g *Game) animate(mv puzzle.Move) {
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	g.turning = &turnAnimation{from: g.state, move: mv, started: time.Now()}
+}
+
+// finishTurn makes the turn being animated on the puzzle
+func (g *Game) finishTurn() {
+	g.turning.move.Apply(&g.state)
+	g.turning = nil
+}
+
+// play shows the start of sol and plays its moves
+func (g *Game) play(sol solution) {
+	g.setState(sol.start)
+	g.editing = false
+	g.player = newPlayer(sol.moves)
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	v := g.view()
+	if g.solid {
+		drawSolid(screen, g.solidPolygons(v), v)
+	} else if g.turning != nil {
+		drawTurn(screen, g.turning, v, g.turning.progress(g.turnTime()))
+	} else {
+		drawFaces(screen, &g.state, v)
+	}
+
+	hud := g.hudImage()
+	g.selectors = drawSelectors(hud)
+	drawMarker(hud, g.selectors, g.selected)
+
+	bottom := g.hudHeight - hudBottom
+	ebitenutil.DebugPrintAt(hud, "To restart, press R", 5, bottom+60)
+	ebitenutil.DebugPrintAt(hud, "To restart and randomize, press T", 5, bottom+80)
+	ebitenutil.DebugPrintAt(hud, "To scramble and solve by layers, press L", 5, bottom+20)
+	ebitenutil.DebugPrintAt(hud, "To scramble and solve in phases, press P", 5, bottom+40)
+
+	ebitenutil.DebugPrintAt(hud, "Clockwise: left arrow", 250, bottom+80)
+	ebitenutil.DebugPrintAt(hud, "Counter-clockwise: right arrow", 250, bottom+60)
+	ebitenutil.DebugPrintAt(hud, "Face keys and help: press /", 250, bottom+40)
+
+	if g.solving != nil {
+		ebitenutil.DebugPrintAt(hud, g.solving.status(), 5, 5)
+	} else if g.message != "" {
+		ebitenutil.DebugPrintAt(hud, g.message, 5, 5)
+	}
+	if g.editing {
+		g.drawEdit(hud, bottom)
+	}
+	if g.player != nil {
+		drawPlayer(hud, g.player, g.hudWidth-historyWidth, bottom)
+	}
+	drawHistory(hud, &g.history, g.hudWidth-historyWidth, g.hudHeight)
+	if g.entry != nil {
+		drawEntry(hud, g.entry, g.hudWidth)
+	}
+	if g.help {
+		drawHelp(hud, g.Keys, g.hudWidth, g.hudHeight)
+	}
+	g.drawHUD(screen, hud)
+}
+
+// Run opens the window for g and returns once it is closed
+func Run(g *Game) error {
+	whiteImage = ebiten.NewImage(3, 3)
+	whiteImage.Fill(color.White)
+	ebiten.SetWindowSize(windowWidth, windowHeight)
+	ebiten.SetWindowSizeLimits(minWidth, minHeight, -1, -1)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowTitle("Megaminx Viewer")
+	return ebiten.RunGame(g)
+}
+
+// Unwind will accept a solved node and display the path from start to solved. It is safe to call from any
+// goroutine, and blocks until the window takes the path
+func (g *Game) Unwind(node solver.Node) {
+	path := solver.Path(node)
+	start := node.State()
+	path.Inverse().Apply(&start)
+	g.unwind <- solution{start, path}
+}
@@ -0,0 +1,167 @@
+//go:build !headless
+
+// This file keeps the history of the turns made by hand, for undo and redo,
+// and draws it as a list next to the puzzle. Clicking a row of the list jumps
+// to the puzzle as it was after that move.
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"megaminx/puzzle"
+)
+
+const (
+	historyWidth = 80 // width of the history list, right of the puzzle
+	historyTop   = 25 // y of the first row of the list
+	rowHeight    = 16
+)
+
+// history is the turns made by hand since the puzzle was last reset, scrambled or given a solution
+type history struct {
+	moves    puzzle.Sequence
+	pos      int // moves made, the ones after were undone
+	replayTo int // moves of the list being redone one after another, see replay
+	scroll   int // first row of the list shown. Row 0 is the start, row i the state after move i
+}
+
+// record adds mv after the moves made, forgetting the ones undone and any being replayed
+func (h *history) record(mv puzzle.Move) {
+	h.moves = append(h.moves[:h.pos], mv)
+	h.pos++
+	h.replayTo = 0
+}
+
+// follow scrolls the list, showing rows rows, so the row of the current state is shown
+func (h *history) follow(rows int) {
+	if h.pos < h.scroll {
+		h.scroll = h.pos
+	}
+	if h.pos >= h.scroll+rows {
+		h.scroll = h.pos - rows + 1
+	}
+}
+
+// historyRows returns how many rows of the list fit in a window height pixels of the HUD high
+func historyRows(height int) int {
+	if rows := (height - historyTop) / rowHeight; rows > 1 {
+		return rows
+	}
+	return 1
+}
+
+// historyRows returns how many rows of the list fit in the window
+func (g *Game) historyRows() int {
+	return historyRows(g.hudHeight)
+}
+
+// undo animates the inverse of the last move made by hand
+func (g *Game) undo() {
+	h := &g.history
+	if h.pos == 0 {
+		return
+	}
+	g.player = nil
+	h.pos--
+	h.replayTo = 0
+	h.follow(g.historyRows())
+	g.animate(h.moves[h.pos].Inverse())
+}
+
+// redo animates the last move undone
+func (g *Game) redo() {
+	h := &g.history
+	if h.pos == len(h.moves) {
+		return
+	}
+	g.player = nil
+	g.animate(h.moves[h.pos])
+	h.pos++
+	h.follow(g.historyRows())
+}
+
+// replay adds moves after the moves made, forgetting the ones undone, and redoes them one at a time as
+// each turn finishes animating
+func (g *Game) replay(moves puzzle.Sequence) {
+	h := &g.history
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	g.player = nil
+	h.moves = append(h.moves[:h.pos], moves...)
+	h.replayTo = len(h.moves)
+}
+
+// jumpHistory makes or undoes moves of the history at once until pos of them are made
+func (g *Game) jumpHistory(pos int) {
+	h := &g.history
+	g.player = nil
+	h.replayTo = 0
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	for ; h.pos < pos; h.pos++ {
+		h.moves[h.pos].Apply(&g.state)
+	}
+	for ; h.pos > pos; h.pos-- {
+		h.moves[h.pos-1].Inverse().Apply(&g.state)
+	}
+}
+
+// updateHistory redoes the next move being replayed, and handles the undo and redo keys, and scrolling and
+// clicking the list
+func (g *Game) updateHistory() {
+	h := &g.history
+	if h.pos < h.replayTo && g.turning == nil {
+		g.redo()
+	}
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	switch {
+	case ctrl && !shift && inpututil.IsKeyJustPressed(ebiten.KeyZ):
+		g.undo()
+	case ctrl && (inpututil.IsKeyJustPressed(ebiten.KeyY) || shift && inpututil.IsKeyJustPressed(ebiten.KeyZ)):
+		g.redo()
+	}
+
+	x, y := g.hudCursor()
+	if x < g.hudWidth-historyWidth {
+		return
+	}
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		h.scroll -= int(dy)
+		if max := len(h.moves) + 1 - g.historyRows(); h.scroll > max {
+			h.scroll = max
+		}
+		if h.scroll < 0 {
+			h.scroll = 0
+		}
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && y >= historyTop {
+		if row := h.scroll + (y-historyTop)/rowHeight; row <= len(h.moves) {
+			g.jumpHistory(row)
+		}
+	}
+}
+
+// drawHistory draws the list of moves from x to the right of the screen, height pixels high, highlighting
+// the row of the state shown
+func drawHistory(screen *ebiten.Image, h *history, x, height int) {
+	vector.DrawFilledRect(screen, float32(x), 0, historyWidth, float32(height), color.Gray{Y: 24}, false)
+	ebitenutil.DebugPrintAt(screen, "history", x+5, 5)
+	for row := h.scroll; row <= len(h.moves) && row < h.scroll+historyRows(height); row++ {
+		y := historyTop + (row-h.scroll)*rowHeight
+		if row == h.pos {
+			vector.DrawFilledRect(screen, float32(x), float32(y), historyWidth, rowHeight, color.Gray{Y: 80}, false)
+		}
+		text := "start"
+		if row > 0 {
+			text = h.moves[row-1].String()
+		}
+		ebitenutil.DebugPrintAt(screen, text, x+5, y)
+	}
+}
@@ -0,0 +1,128 @@
+//go:build !headless
+
+// This file contains the keyboard bindings of the faces and the help overlay
+// listing every key of the window. Each face has a key turning it clockwise,
+// and counter-clockwise with shift held.
+
+package gui
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"megaminx/puzzle"
+	"os"
+)
+
+// Bindings holds the key turning each face
+type Bindings [12]ebiten.Key
+
+// DefaultBindings lays the faces out on the keyboard as they are drawn: the top half around S for the left
+// hand, and the bottom half around J for the right one
+var DefaultBindings = Bindings{
+	ebiten.KeyS, ebiten.KeyX, ebiten.KeyD, ebiten.KeyE, ebiten.KeyW, ebiten.KeyA, // U F R BR BL L
+	ebiten.KeyJ, ebiten.KeyM, ebiten.KeyK, ebiten.KeyI, ebiten.KeyU, ebiten.KeyH, // D B DBR DR DL DBL
+}
+
+// commandKeys are the other keys of the window, which faces can not be bound to, and what they do
+var commandKeys = []struct {
+	key  ebiten.Key
+	help string
+}{
+	{ebiten.KeyArrowLeft, "selected face clockwise"},
+	{ebiten.KeyArrowRight, "selected face anticlockwise"},
+	{ebiten.KeyT, "scramble, solve with A*"},
+	{ebiten.KeyL, "scramble, solve by layers"},
+	{ebiten.KeyP, "scramble, solve in phases"},
+	{ebiten.KeyR, "reset"},
+	{ebiten.KeyEscape, "cancel solve"},
+	{ebiten.KeySpace, "play/pause solution"},
+	{ebiten.KeyComma, "step solution back"},
+	{ebiten.KeyPeriod, "step solution forward"},
+	{ebiten.KeyHome, "jump to solution start"},
+	{ebiten.KeyEnd, "jump to solution end"},
+	{ebiten.KeyMinus, "slower playback"},
+	{ebiten.KeyEqual, "faster playback"},
+	{ebiten.KeyV, "switch net/3D view"},
+	{ebiten.Key0, "reset zoom and pan"},
+	{ebiten.KeyTab, "edit stickers"},
+	{ebiten.KeyEnter, "solve stickers edited"},
+	{ebiten.KeyN, "type moves to make"},
+	{ebiten.KeySlash, "show/hide this help"},
+}
+
+// LoadBindings reads a JSON object binding face names to key names, such as {"U": "S", "DBL": "H"}, from
+// path. Faces left out keep their default keys
+func LoadBindings(path string) (Bindings, error) {
+	b := DefaultBindings
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	var keys map[string]ebiten.Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return b, fmt.Errorf("%s: %v", path, err)
+	}
+	for name, key := range keys {
+		face, ok := puzzle.FaceByName(name)
+		if !ok {
+			return b, fmt.Errorf("%s: unknown face %q", path, name)
+		}
+		b[face] = key
+	}
+	return b, b.check()
+}
+
+// check returns an error if two faces share a key or a face has the key of a command
+func (b Bindings) check() error {
+	for face, key := range b {
+		for other := 0; other < face; other++ {
+			if b[other] == key {
+				return fmt.Errorf("%s and %s are both bound to %s", puzzle.FaceName(other), puzzle.FaceName(face), key)
+			}
+		}
+		for _, cmd := range commandKeys {
+			if cmd.key == key {
+				return fmt.Errorf("%s is bound to %s, which is taken: %s", puzzle.FaceName(face), key, cmd.help)
+			}
+		}
+	}
+	return nil
+}
+
+// updateKeys turns the faces whose keys were just pressed. Ctrl and cmd leave the keys to the shortcuts,
+// and no face turns in the edit mode
+func (g *Game) updateKeys() {
+	if g.editing || ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta) {
+		return
+	}
+	turns := 1
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		turns = -1
+	}
+	for face, key := range g.Keys {
+		if inpututil.IsKeyJustPressed(key) {
+			g.selected = face
+			g.turnByHand(puzzle.Move{Face: face, Turns: turns})
+		}
+	}
+}
+
+// drawHelp draws the keys of the faces and the commands over a window width by height pixels
+func drawHelp(screen *ebiten.Image, b Bindings, width, height int) {
+	const x, y = 20, 10
+	vector.DrawFilledRect(screen, x, y, float32(width-2*x), float32(height-2*y), color.RGBA{A: 224}, false)
+	ebitenutil.DebugPrintAt(screen, "Face keys turn clockwise, with shift counter-clockwise", x+10, y+5)
+	for face, key := range b {
+		col, row := face/6, face%6
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%-4s %s", puzzle.FaceName(face), key), x+10+col*100, y+25+row*rowHeight)
+	}
+	for i, cmd := range commandKeys {
+		col, row := i/10, i%10
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%-10s %s", cmd.key, cmd.help), x+10+col*240, y+135+row*rowHeight)
+	}
+}
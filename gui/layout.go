@@ -0,0 +1,133 @@
+//go:build !headless
+
+// This file lays the window out for its size. The puzzle fills the space the
+// HUD leaves, and is drawn in the pixels of the display so it stays sharp on
+// high-DPI screens; the HUD, the selectors, text and history list, is drawn in
+// the pixels of the window and scaled up by the device scale factor, so its
+// text keeps its size. The mouse wheel zooms the puzzle and the middle button
+// pans it.
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"math"
+)
+
+const (
+	windowWidth  = 900 // size the window opens at
+	windowHeight = 600
+	minWidth     = 520 // smallest size the window can be made
+	minHeight    = 320
+	hudTop       = 64  // height of the strip at the top holding the selectors and the playback
+	hudBottom    = 100 // height of the strip at the bottom holding the hints
+	netWidth     = 23  // size of the net, in faces
+	netHeight    = 11
+	zoomStep     = 1.1 // zoom of a notch of the mouse wheel
+	minZoom      = 0.25
+	maxZoom      = 8
+)
+
+// view is where the puzzle is drawn, in pixels of the screen
+type view struct {
+	x, y  float32 // center of the net or the solid
+	scale float32 // size of a face in the net
+}
+
+// pan is a drag of the puzzle with the middle button
+type pan struct {
+	x, y       int // where the drag started
+	panX, panY float64
+}
+
+// Layout makes the screen as big as the window is in pixels of the display, and keeps the size of the
+// window for the HUD
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	g.deviceScale = ebiten.DeviceScaleFactor()
+	g.hudWidth, g.hudHeight = outsideWidth, outsideHeight
+	return int(math.Ceil(float64(outsideWidth) * g.deviceScale)), int(math.Ceil(float64(outsideHeight) * g.deviceScale))
+}
+
+// view returns where the puzzle is drawn: fit to the space between the strips of the HUD and left of the
+// history list, then zoomed and panned
+func (g *Game) view() view {
+	w, h := float64(g.hudWidth-historyWidth), float64(g.hudHeight-hudTop-hudBottom)
+	fit := math.Max(math.Min(w/netWidth, h/netHeight), 1)
+	return view{
+		x:     float32((w/2 + g.panX) * g.deviceScale),
+		y:     float32((hudTop + h/2 + g.panY) * g.deviceScale),
+		scale: float32(fit * g.zoom * g.deviceScale),
+	}
+}
+
+// hudCursor returns where the cursor is in pixels of the HUD
+func (g *Game) hudCursor() (int, int) {
+	x, y := ebiten.CursorPosition()
+	return int(float64(x) / g.deviceScale), int(float64(y) / g.deviceScale)
+}
+
+// inPuzzle reports whether (x, y), in pixels of the screen, is in the space the puzzle is drawn in rather
+// than on the HUD
+func (g *Game) inPuzzle(x, y int) bool {
+	hx, hy := float64(x)/g.deviceScale, float64(y)/g.deviceScale
+	return hx < float64(g.hudWidth-historyWidth) && hy >= hudTop && hy < float64(g.hudHeight-hudBottom)
+}
+
+// dragged reports whether the mouse went far enough from where it was pressed, dx and dy pixels of the
+// screen away, for the press not to be a click
+func (g *Game) dragged(dx, dy int) bool {
+	return math.Hypot(float64(dx), float64(dy)) >= dragDistance*g.deviceScale
+}
+
+// updateView zooms the puzzle with the wheel, keeping the point under the cursor in place, pans it while
+// the middle button is held and puts it back with 0
+func (g *Game) updateView() {
+	x, y := ebiten.CursorPosition()
+	if _, dy := ebiten.Wheel(); dy != 0 && g.inPuzzle(x, y) {
+		zoom := math.Max(minZoom, math.Min(maxZoom, g.zoom*math.Pow(zoomStep, dy)))
+		v := g.view()
+		k := zoom / g.zoom
+		g.panX += (float64(x) - float64(v.x)) / g.deviceScale * (1 - k)
+		g.panY += (float64(y) - float64(v.y)) / g.deviceScale * (1 - k)
+		g.zoom = zoom
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonMiddle) && g.inPuzzle(x, y) {
+		g.panning = &pan{x, y, g.panX, g.panY}
+	}
+	if p := g.panning; p != nil {
+		g.panX = p.panX + float64(x-p.x)/g.deviceScale
+		g.panY = p.panY + float64(y-p.y)/g.deviceScale
+		if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonMiddle) {
+			g.panning = nil
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.Key0) {
+		g.zoom, g.panX, g.panY, g.panning = 1, 0, 0, nil
+	}
+}
+
+// hudImage returns the image the HUD is drawn on, cleared and as big as the window in its own pixels
+func (g *Game) hudImage() *ebiten.Image {
+	w, h := g.hudWidth, g.hudHeight
+	if w < 1 || h < 1 { // a minimized window
+		w, h = 1, 1
+	}
+	if g.hud == nil || g.hud.Bounds().Dx() != w || g.hud.Bounds().Dy() != h {
+		if g.hud != nil {
+			g.hud.Dispose()
+		}
+		g.hud = ebiten.NewImage(w, h)
+	}
+	g.hud.Clear()
+	return g.hud
+}
+
+// drawHUD draws hud over the screen, scaled to pixels of the display
+func (g *Game) drawHUD(screen, hud *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(g.deviceScale, g.deviceScale)
+	screen.DrawImage(hud, op)
+}
@@ -0,0 +1,122 @@
+//go:build !headless
+
+// This file turns faces with the mouse. Clicking the center of a face turns it
+// clockwise, or counter-clockwise with the right button or shift, and dragging
+// across a face turns it the way the drag goes around its center.
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"megaminx/puzzle"
+)
+
+// dragDistance is how far, in pixels of the HUD, the mouse must move for a press to be a drag rather than a click
+const dragDistance = 5
+
+// press is a mouse button held down on a face
+type press struct {
+	x, y   int
+	face   int
+	tile   int // -1 for the center
+	button ebiten.MouseButton
+}
+
+// inPolygon reports whether (x, y) is inside the polygon with corners vs
+func inPolygon(vs []ebiten.Vertex, x, y float32) bool {
+	in := false
+	for i, j := 0, len(vs)-1; i < len(vs); j, i = i, i+1 {
+		a, b := vs[i], vs[j]
+		if (a.DstY > y) != (b.DstY > y) && x < (b.DstX-a.DstX)*(y-a.DstY)/(b.DstY-a.DstY)+a.DstX {
+			in = !in
+		}
+	}
+	return in
+}
+
+// faceAt returns the face drawn at (x, y) and its tile there, -1 for the center. ok is false if there is
+// no face there
+func faceAt(x, y float32, v view) (face, tile int, ok bool) {
+	faces, _ := netVertices(v)
+	for face, vs := range faces {
+		if inPolygon(vs[:5], x, y) {
+			return face, -1, true
+		}
+		for tile := 0; tile < 10; tile++ {
+			if inPolygon(vs[6+5*tile:10+5*tile], x, y) {
+				return face, tile, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// clockwiseOnScreen reports whether a clockwise turn of face moves its stickers clockwise as drawn, which
+// the reflections of the net decide
+func clockwiseOnScreen(face int, v view) bool {
+	faces, _ := netVertices(v)
+	vs := faces[face]
+	src := int(puzzle.Move{Face: face, Turns: 1}.Sources()[face][0]) % 10
+	cx, cy := faceCenter(vs)
+	sx, sy := stickerCenter(vs, src)
+	tx, ty := stickerCenter(vs, 0)
+	return (sx-cx)*(ty-cy)-(sy-cy)*(tx-cx) > 0 // y grows downwards, so a positive cross product is clockwise
+}
+
+// click handles a click with button on tile of face, -1 for the center. In the edit mode it paints the
+// sticker, otherwise it selects the face and turns it if its center was clicked, counter-clockwise with the
+// right button or shift
+func (g *Game) click(face, tile int, button ebiten.MouseButton) {
+	alt := button == ebiten.MouseButtonRight || ebiten.IsKeyPressed(ebiten.KeyShift)
+	if g.editing {
+		g.paint(face, tile, alt)
+		return
+	}
+	g.selected = face
+	if tile == -1 {
+		turns := 1
+		if alt {
+			turns = -1
+		}
+		g.turnByHand(puzzle.Move{Face: face, Turns: turns})
+	}
+}
+
+// updateMouse turns the face clicked or dragged across in the net drawn in v
+func (g *Game) updateMouse(v view) {
+	x, y := ebiten.CursorPosition()
+	for _, button := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight} {
+		if inpututil.IsMouseButtonJustPressed(button) && g.inPuzzle(x, y) {
+			if face, tile, ok := faceAt(float32(x), float32(y), v); ok {
+				g.pressed = &press{x, y, face, tile, button}
+			}
+		}
+	}
+	p := g.pressed
+	if p == nil || !inpututil.IsMouseButtonJustReleased(p.button) {
+		return
+	}
+	g.pressed = nil
+	if !g.dragged(x-p.x, y-p.y) {
+		g.click(p.face, p.tile, p.button)
+		return
+	}
+	if g.editing {
+		return
+	}
+	g.selected = p.face
+
+	faces, _ := netVertices(v)
+	cx, cy := faceCenter(faces[p.face])
+	dx, dy := float32(x-p.x), float32(y-p.y)
+	cross := (float32(p.x)-cx)*dy - (float32(p.y)-cy)*dx
+	if cross == 0 {
+		return // dragged straight through the center
+	}
+	turns := 1
+	if (cross > 0) != clockwiseOnScreen(p.face, v) {
+		turns = -1
+	}
+	g.turnByHand(puzzle.Move{Face: p.face, Turns: turns})
+}
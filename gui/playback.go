@@ -0,0 +1,131 @@
+//go:build !headless
+
+// This file contains the playback controls of solutions. The player keeps the
+// whole solution and how far into it the puzzle is, so it can step both ways:
+// stepping back turns the inverse of the last move made rather than going
+// back to a stored state.
+
+package gui
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"megaminx/puzzle"
+	"time"
+)
+
+// speeds are the playback speeds, as multiples of Game.TurnTime
+var speeds = []float64{0.25, 0.5, 1, 2, 4}
+
+// player plays a solution
+type player struct {
+	moves   puzzle.Sequence
+	pos     int  // moves of the solution made, counting the one being animated
+	playing bool // whether the moves after pos are made one after the other
+	speed   int  // index into speeds
+}
+
+// newPlayer returns a player playing moves from the start
+func newPlayer(moves puzzle.Sequence) *player {
+	return &player{moves: moves, playing: true, speed: 2}
+}
+
+// turnTime returns the time a turn takes to animate, TurnTime at the speed of the playback if any
+func (g *Game) turnTime() time.Duration {
+	if g.player == nil {
+		return g.TurnTime
+	}
+	return time.Duration(float64(g.TurnTime) / speeds[g.player.speed])
+}
+
+// stepForward animates the next move of the solution
+func (g *Game) stepForward() {
+	p := g.player
+	if p.pos == len(p.moves) {
+		return
+	}
+	g.animate(p.moves[p.pos])
+	p.pos++
+}
+
+// stepBack animates the inverse of the last move of the solution made
+func (g *Game) stepBack() {
+	p := g.player
+	if p.pos == 0 {
+		return
+	}
+	p.pos--
+	g.animate(p.moves[p.pos].Inverse())
+}
+
+// jump makes or undoes moves of the solution at once until pos of them are made
+func (g *Game) jump(pos int) {
+	p := g.player
+	if g.turning != nil {
+		g.finishTurn()
+	}
+	for ; p.pos < pos; p.pos++ {
+		p.moves[p.pos].Apply(&g.state)
+	}
+	for ; p.pos > pos; p.pos-- {
+		p.moves[p.pos-1].Inverse().Apply(&g.state)
+	}
+}
+
+// updatePlayer handles the playback keys and makes the next move when playing
+func (g *Game) updatePlayer() {
+	p := g.player
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		if p.pos == len(p.moves) { // playing a finished solution plays it again
+			g.jump(0)
+		}
+		p.playing = !p.playing
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		p.playing = false
+		g.stepForward()
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		p.playing = false
+		g.stepBack()
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		p.playing = false
+		g.jump(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		p.playing = false
+		g.jump(len(p.moves))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual) && p.speed < len(speeds)-1:
+		p.speed++
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus) && p.speed > 0:
+		p.speed--
+	}
+
+	if p.playing && g.turning == nil {
+		if p.pos == len(p.moves) {
+			p.playing = false
+		} else {
+			g.stepForward()
+		}
+	}
+}
+
+// drawPlayer draws the move counter, speed and progress bar of the playback, the bar reaching to width,
+// and its keys at bottom
+func drawPlayer(screen *ebiten.Image, p *player, width, bottom int) {
+	state := "paused"
+	if p.playing {
+		state = "playing"
+	}
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d/%d %s %gx", p.pos, len(p.moves), state, speeds[p.speed]), 5, 40)
+
+	const x, y, h = 140, 46, 4
+	w := float32(width - x - 10)
+	vector.DrawFilledRect(screen, x, y, w, h, color.Gray{Y: 64}, false)
+	if len(p.moves) > 0 {
+		vector.DrawFilledRect(screen, x, y, w*float32(p.pos)/float32(len(p.moves)), h, color.White, false)
+	}
+	ebitenutil.DebugPrintAt(screen, "Space: play/pause  , .: step  Home/End: jump  - +: speed", 5, bottom)
+}
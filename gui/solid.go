@@ -0,0 +1,120 @@
+//go:build !headless
+
+// This file contains the 3D view, which draws the puzzle as a dodecahedron
+// with render.SolidPolygons. Dragging with the mouse orbits the camera, and
+// clicking the center of a face turns it as in the net.
+
+package gui
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"math"
+	"megaminx/render"
+)
+
+const (
+	solidRadius = 5.5  // radius of the dodecahedron in the 3D view, in faces of the net, so it is as tall
+	orbitSpeed  = 0.01 // radians the camera turns per pixel of the HUD dragged
+)
+
+// defaultCamera looks at F from a little above, with R to its right
+var defaultCamera = render.Camera{Yaw: 0.3, Pitch: 0.5}
+
+// orbit is a drag of the camera
+type orbit struct {
+	x, y   int // where the drag started
+	camera render.Camera
+	moved  bool // whether the mouse went far enough for the press not to be a click
+}
+
+// solidPolygons returns the polygons of the 3D view as drawn now in v, centered on the origin
+func (g *Game) solidPolygons(v view) []render.Polygon {
+	radius := float64(v.scale) * solidRadius
+	if g.turning != nil {
+		turn := &render.Turn{Move: g.turning.move, Progress: g.turning.progress(g.turnTime())}
+		return render.SolidPolygons(&g.turning.from, g.camera, turn, radius)
+	}
+	return render.SolidPolygons(&g.state, g.camera, nil, radius)
+}
+
+// drawSolid draws polys centered on the center of v
+func drawSolid(screen *ebiten.Image, polys []render.Polygon, v view) {
+	var vs []ebiten.Vertex
+	var is []uint16
+	for _, p := range polys {
+		base := uint16(len(vs))
+		for i, pt := range p.Points {
+			vs = append(vs, ebiten.Vertex{
+				DstX:   float32(pt.X) + v.x,
+				DstY:   float32(pt.Y) + v.y,
+				ColorR: float32(p.Color.R) / 255,
+				ColorG: float32(p.Color.G) / 255,
+				ColorB: float32(p.Color.B) / 255,
+				ColorA: 1,
+			})
+			if i >= 2 {
+				is = append(is, base, base+uint16(i-1), base+uint16(i))
+			}
+		}
+	}
+	op := &ebiten.DrawTrianglesOptions{}
+	op.AntiAlias = true
+	screen.DrawTriangles(vs, is, whiteImage, op)
+}
+
+// updateOrbit turns the camera while the left button is dragged, and turns the face whose center is
+// clicked, counter-clockwise with the right button or shift, in the solid drawn in v
+func (g *Game) updateOrbit(v view) {
+	x, y := ebiten.CursorPosition()
+	for _, button := range []ebiten.MouseButton{ebiten.MouseButtonLeft, ebiten.MouseButtonRight} {
+		if inpututil.IsMouseButtonJustPressed(button) && g.inPuzzle(x, y) {
+			g.orbit = &orbit{x: x, y: y, camera: g.camera}
+		}
+	}
+	o := g.orbit
+	if o == nil {
+		return
+	}
+
+	dx, dy := x-o.x, y-o.y
+	if g.dragged(dx, dy) {
+		o.moved = true
+	}
+	if o.moved && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		speed := orbitSpeed / g.deviceScale
+		g.camera.Yaw = o.camera.Yaw - float64(dx)*speed
+		g.camera.Pitch = math.Max(-math.Pi/2, math.Min(math.Pi/2, o.camera.Pitch+float64(dy)*speed))
+	}
+
+	left, right := inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft), inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight)
+	if !left && !right {
+		return
+	}
+	g.orbit = nil
+	if o.moved {
+		return
+	}
+	polys := g.solidPolygons(v)
+	for i := len(polys) - 1; i >= 0; i-- { // the polygon drawn last is on top
+		p := polys[i]
+		if !inPolygonPoints(p.Points, float64(x)-float64(v.x), float64(y)-float64(v.y)) {
+			continue
+		}
+		button := ebiten.MouseButtonLeft
+		if right {
+			button = ebiten.MouseButtonRight
+		}
+		g.click(p.Face, p.Tile, button)
+		return
+	}
+}
+
+// inPolygonPoints reports whether (x, y) is inside the polygon with corners pts
+func inPolygonPoints(pts []render.Point, x, y float64) bool {
+	vs := make([]ebiten.Vertex, len(pts))
+	for i, p := range pts {
+		vs[i].DstX, vs[i].DstY = float32(p.X), float32(p.Y)
+	}
+	return inPolygon(vs, float32(x), float32(y))
+}
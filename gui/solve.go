@@ -0,0 +1,135 @@
+//go:build !headless
+
+// This file runs solves in the background, so the window keeps drawing while
+// a search takes minutes. Update polls the running solve each frame and hands
+// the solution to the playback stack once it arrives.
+
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"megaminx/puzzle"
+	"megaminx/solver"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxStageLine is the length past which the line of a stage is cut in the HUD
+const maxStageLine = 100
+
+// errStopped is returned by a search closed through its stop channel
+var errStopped = errors.New("stopped")
+
+// solveFunc solves s, ending early if stop is closed and calling progress with the nodes expanded so far.
+// It returns the solution and a line summing it up for the HUD
+type solveFunc func(s puzzle.State, stop <-chan struct{}, progress func(expanded int)) (puzzle.Sequence, string, error)
+
+// solveResult is what a background solve hands back to Update
+type solveResult struct {
+	solution
+	summary string
+	err     error
+}
+
+// solving is a solve running in the background
+type solving struct {
+	name     string
+	started  time.Time
+	expanded int64 // nodes expanded so far, read and written atomically
+	stop     chan struct{}
+	done     chan solveResult
+}
+
+// startSolve solves the puzzle shown in the background with solve, named name in the status line
+func (g *Game) startSolve(name string, solve solveFunc) {
+	sv := &solving{
+		name:    name,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan solveResult, 1), // never blocks, so a cancelled solve can still finish
+	}
+	start := g.state
+	go func() {
+		moves, summary, err := solve(start, sv.stop, func(expanded int) {
+			atomic.StoreInt64(&sv.expanded, int64(expanded))
+		})
+		sv.done <- solveResult{solution{start, moves}, summary, err}
+	}()
+	g.solving = sv
+	g.message = ""
+}
+
+// pollSolve plays the solution of the running solve if it has finished, leaving its summary or error in
+// the HUD
+func (g *Game) pollSolve() {
+	select {
+	case res := <-g.solving.done:
+		name := g.solving.name
+		g.solving = nil
+		if res.err != nil {
+			g.message = fmt.Sprintf("solving %s failed: %s", name, res.err)
+			return
+		}
+		g.message = res.summary
+		g.play(res.solution)
+	default:
+	}
+}
+
+// cancelSolve stops the running solve. Layers and phases can not be stopped, so they run to the end and
+// their solution is dropped
+func (g *Game) cancelSolve() {
+	close(g.solving.stop)
+	g.message = fmt.Sprintf("solving %s cancelled", g.solving.name)
+	g.solving = nil
+}
+
+// status returns the status line of the running solve
+func (sv *solving) status() string {
+	elapsed := time.Since(sv.started).Round(100 * time.Millisecond)
+	if expanded := atomic.LoadInt64(&sv.expanded); expanded > 0 {
+		return fmt.Sprintf("solving %s... %d nodes, %s (Esc cancels)", sv.name, expanded, elapsed)
+	}
+	return fmt.Sprintf("solving %s... %s (Esc cancels)", sv.name, elapsed)
+}
+
+// searchSolve solves with A* through solver.SolveWith, returning the simplified solution
+func searchSolve(s puzzle.State, stop <-chan struct{}, progress func(int)) (puzzle.Sequence, string, error) {
+	node, stats, ok := solver.SolveWith(s, stop, func(st solver.Stats) {
+		progress(st.Expanded)
+	})
+	if !ok {
+		if stats.Stopped {
+			return nil, "", errStopped
+		}
+		return nil, "", fmt.Errorf("no solution found after expanding %d nodes", stats.Expanded)
+	}
+	path := solver.Path(node)
+	simple := solver.Simplify(path)
+	return simple, fmt.Sprintf("%d moves, %d before simplification, %d nodes expanded", len(simple), len(path), stats.Expanded), nil
+}
+
+// stagesSolve returns a solveFunc solving with solve and returning the simplified solution, summed up with
+// a line per stage. Neither stop nor progress are used
+func stagesSolve(solve func(s puzzle.State) ([]solver.Stage, error)) solveFunc {
+	return func(s puzzle.State, stop <-chan struct{}, progress func(int)) (puzzle.Sequence, string, error) {
+		stages, err := solve(s)
+		if err != nil {
+			return nil, "", err
+		}
+		moves := solver.JoinStages(stages)
+		simple := solver.Simplify(moves)
+		var summary strings.Builder
+		fmt.Fprintf(&summary, "%d moves in %d stages, %d before simplification", len(simple), len(stages), len(moves))
+		for _, st := range stages {
+			line := fmt.Sprintf("%s (%d): %s", st.Name, len(st.Moves), st.Moves)
+			if len(line) > maxStageLine {
+				line = line[:maxStageLine-3] + "..."
+			}
+			summary.WriteString("\n" + line)
+		}
+		return simple, summary.String(), nil
+	}
+}
@@ -0,0 +1,16 @@
+//go:build headless
+
+// This file stands in for the puzzle window in headless builds, made with
+// -tags headless, which leave out ebiten and so run without a display.
+
+package main
+
+import "errors"
+
+// errHeadless is returned when the window is asked for in a headless build
+var errHeadless = errors.New("built without the window (-tags headless), give a command such as solve or bench")
+
+// runGUI reports that there is no window to open
+func runGUI() error {
+	return errHeadless
+}
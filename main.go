@@ -1,122 +1,78 @@
+// Command megaminx is the viewer and command line of the megaminx solver, a thin
+// client of the puzzle, notation, solver, render and gui packages.
 package main
 
 import (
 	"flag"
-	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"image/color"
+	"fmt"
 	"log"
 	"math/rand"
+	"megaminx/solver"
+	"os"
 	"time"
 )
 
-const (
-	screenWidth  = 450
-	screenHeight = 300
-	rotations    = 10
-)
-
 var (
-	whiteImage = ebiten.NewImage(3, 3)
+	tablesPath  = flag.String("tables", solver.DefaultTablesPath(), "pruning table file loaded at startup to speed up solving")
+	buildTables = flag.Bool("build-tables", false, "build the pruning tables, write them to the -tables file and exit")
+	cachePath   = flag.String("cache", "", "file the solution cache is loaded from at startup and saved to on exit")
 )
 
-var selectors = make([][]ebiten.Vertex, 12)
-
-var gui bool
-
 func init() {
-	guiFlag := flag.Bool("gui", false, "if gui flag is set, the gui will be displayed. otherwise, the solver will be ran.")
-	flag.Parse()
-	gui = *guiFlag
 	rand.Seed(time.Now().UnixNano()) // seed random number generator for randomizer
-	whiteImage.Fill(color.White)
-	state = NewState()
-}
-
-var state State
-
-type Game struct {
-	frame    int
-	selected int
-	stack    []Node // stack of nodes to unwind. if len(stack) == 0, no nodes to unwind
 }
 
-func (g *Game) Update() error {
-	g.frame = (g.frame + 1) % 60
-
-	if g.frame%60 == 0 && len(g.stack) != 0 {
-		state = *(g.stack[len(g.stack)-1]).s
-		g.stack = g.stack[:len(g.stack)-1] // pop off last element in stack
+// loadTables builds the pruning tables if -build-tables is set, and otherwise loads them if they have been built.
+// It reports whether the program should go on
+func loadTables() bool {
+	if *buildTables {
+		start := time.Now()
+		t := solver.BuildTables()
+		if err := t.WriteFile(*tablesPath); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("built pruning tables in %s, written to %s\n", time.Since(start), *tablesPath)
+		return false
 	}
-
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) { // if left click just pressed
-		// figure out where
-		xi, yi := ebiten.CursorPosition()
-		x := float32(xi)
-		y := float32(yi)
-		for i, s := range selectors {
-			if x >= s[0].DstX && x <= s[1].DstX && y >= s[0].DstY && y <= s[2].DstY {
-				g.selected = i
-				break
-			}
+	t, err := solver.LoadTables(*tablesPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Println(err) // a damaged or outdated file, rebuild it with -build-tables
 		}
+		return true
 	}
+	solver.UseTables(t)
+	return true
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
-		state.CW(g.selected)
-	}
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	os.Exit(run())
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
-		state.CCW(g.selected)
+// run runs the command given on the command line and returns the exit status
+func run() int {
+	if !loadTables() {
+		return 0
 	}
-
-	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
-		state = NewState()
-		state.randomize(rotations)
-		_, node := Solve(state)
-		var stack []Node
-		for {
-			stack = append(stack, node)
-			if node.prev == nil {
-				break
-			}
-			node = *(node.prev)
+	if *cachePath != "" {
+		if err := solver.DefaultCache.Load(*cachePath); err != nil && !os.IsNotExist(err) {
+			log.Println(err)
 		}
-		g.stack = stack
+		defer func() {
+			if err := solver.DefaultCache.Save(*cachePath); err != nil {
+				log.Println(err)
+			}
+		}()
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
-		state = NewState()
+	if flag.NArg() > 0 {
+		return runCommand(flag.Arg(0), flag.Args()[1:])
 	}
-
-	return nil
-}
-
-func (g *Game) Draw(screen *ebiten.Image) {
-	drawFaces(screen, float32(18))
-	drawSelectors(screen)
-	drawMarker(screen, g.selected)
-
-	ebitenutil.DebugPrintAt(screen, "To restart, press R", 5, 260)
-	ebitenutil.DebugPrintAt(screen, "To restart and randomize, press T", 5, 280)
-
-	ebitenutil.DebugPrintAt(screen, "Clockwise: left arrow", 250, 280)
-	ebitenutil.DebugPrintAt(screen, "Counter-clockwise: right arrow", 250, 260)
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return screenWidth, screenHeight
-}
-
-func main() {
-	ebiten.SetWindowSize(screenWidth*2, screenHeight*2)
-	ebiten.SetWindowTitle("Megaminx Viewer")
-	if err := ebiten.RunGame(&Game{
-		frame:    0,
-		selected: 0,
-	}); err != nil {
-		log.Fatal(err)
+	if err := runGUI(); err != nil {
+		log.Println(err)
+		return 1
 	}
-
+	return 0
 }
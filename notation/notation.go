@@ -0,0 +1,138 @@
+// Package notation reads and writes the text formats of the puzzle: moves in
+// face turn notation, and states written as one character per sticker.
+package notation
+
+import (
+	"fmt"
+	"megaminx/puzzle"
+	"strings"
+	"unicode"
+)
+
+// ParseError describes a token that could not be parsed and where it starts in the input
+type ParseError struct {
+	Pos   int // byte offset of the token in the input
+	Token string
+	Msg   string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("position %d: %q: %s", e.Pos+1, e.Token, e.Msg)
+}
+
+// token is a word of the input and its byte offset
+type token struct {
+	text string
+	pos  int
+}
+
+// tokens splits text at whitespace and commas, remembering where every word starts
+func tokens(text string) []token {
+	var res []token
+	start := -1
+	for i, r := range text {
+		sep := unicode.IsSpace(r) || r == ','
+		if sep && start >= 0 {
+			res = append(res, token{text[start:i], start})
+			start = -1
+		} else if !sep && start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		res = append(res, token{text[start:], start})
+	}
+	return res
+}
+
+// ParseSequence parses moves in face turn notation, as printed by puzzle.Sequence.String: a face name followed by
+// nothing for a clockwise turn, ' for counter-clockwise, 2 for two fifths clockwise and 2' for two fifths
+// counter-clockwise. Face names are not case sensitive, and moves are separated by spaces or commas
+func ParseSequence(text string) (puzzle.Sequence, error) {
+	var res puzzle.Sequence
+	for _, tok := range tokens(text) {
+		mv, err := parseMove(tok.text)
+		if err != nil {
+			return nil, &ParseError{tok.pos, tok.text, err.Error()}
+		}
+		res = append(res, mv)
+	}
+	return res, nil
+}
+
+// parseMove parses a single move
+func parseMove(text string) (puzzle.Move, error) {
+	name := strings.TrimRight(text, "2'")
+	suffix := text[len(name):]
+	face, ok := puzzle.FaceByName(strings.ToUpper(name))
+	if !ok {
+		return puzzle.Move{}, fmt.Errorf("unknown face %q", name)
+	}
+	switch suffix {
+	case "":
+		return puzzle.Move{Face: face, Turns: 1}, nil
+	case "'":
+		return puzzle.Move{Face: face, Turns: -1}, nil
+	case "2":
+		return puzzle.Move{Face: face, Turns: 2}, nil
+	case "2'", "'2":
+		return puzzle.Move{Face: face, Turns: -2}, nil
+	}
+	return puzzle.Move{}, fmt.Errorf("unknown turn %q", suffix)
+}
+
+// stickerDigits are the characters of the sticker colors in the text format of a state
+const stickerDigits = "0123456789ab"
+
+// FormatState returns s as 12 groups of 10 characters, one group per face and one character per sticker
+// giving the face whose color it has, 0-9 or a-b
+func FormatState(s *puzzle.State) string {
+	var b strings.Builder
+	for f := 0; f < 12; f++ {
+		if f > 0 {
+			b.WriteByte(' ')
+		}
+		for t := 0; t < 10; t++ {
+			b.WriteByte(stickerDigits[s[f][t]%12])
+		}
+	}
+	return b.String()
+}
+
+// ParseState parses a state in the format of FormatState
+func ParseState(text string) (puzzle.State, error) {
+	var s puzzle.State
+	toks := tokens(text)
+	if len(toks) != 12 {
+		return s, fmt.Errorf("a state has 12 faces, found %d", len(toks))
+	}
+	for f, tok := range toks {
+		if len(tok.text) != 10 {
+			return s, &ParseError{tok.pos, tok.text, "a face has 10 stickers"}
+		}
+		for t := 0; t < 10; t++ {
+			c := strings.IndexByte(stickerDigits, byte(unicode.ToLower(rune(tok.text[t]))))
+			if c < 0 {
+				return s, &ParseError{tok.pos + t, tok.text[t : t+1], "stickers are 0-9, a or b"}
+			}
+			s[f][t] = byte(c)
+		}
+	}
+	return s, nil
+}
+
+// ParsePosition parses either a state or a scramble, in face turn or Pochmann notation, which is applied to the
+// solved state
+func ParsePosition(text string) (puzzle.State, error) {
+	toks := tokens(text)
+	if len(toks) == 12 && len(toks[0].text) == 10 { // no move is 10 characters long
+		return ParseState(text)
+	}
+	seq, err := ParseMoves(text)
+	if err != nil {
+		return puzzle.State{}, err
+	}
+	s := puzzle.NewState()
+	seq.Apply(&s)
+	return s, nil
+}
@@ -0,0 +1,128 @@
+// This file reads Pochmann notation, which WCA scrambles are written in. Its
+// R and D moves turn the whole puzzle but one face, which as the centers of
+// the state never move is the same as turning that face and then looking at
+// the puzzle from another side, so the faces the later moves turn are tracked
+// as the puzzle is turned over.
+
+package notation
+
+import (
+	"megaminx/puzzle"
+	"strings"
+)
+
+// pochmannMoves are the moves of Pochmann notation: the face held still, named where it is before the move,
+// and how far it turns against the rest of the puzzle. R++ turns all but L two fifths clockwise as seen
+// from the right, leaving L two fifths clockwise against the rest, and D++ does the same to all but U as
+// seen from below
+var pochmannMoves = map[string]struct {
+	face, turns int
+	rest        bool // whether the rest of the puzzle turns, rather than the face
+}{
+	"R++": {faceByName("L"), 2, true},
+	"R--": {faceByName("L"), -2, true},
+	"D++": {faceByName("U"), 2, true},
+	"D--": {faceByName("U"), -2, true},
+	"U":   {faceByName("U"), 1, false},
+	"U'":  {faceByName("U"), -1, false},
+}
+
+// faceByName returns the face called name, which must exist
+func faceByName(name string) int {
+	face, ok := puzzle.FaceByName(name)
+	if !ok {
+		panic("notation: no face " + name)
+	}
+	return face
+}
+
+// ParsePochmann parses moves in Pochmann notation, holding the puzzle with U on top and F in front: R++ and
+// R-- turn all but L two fifths clockwise and counter-clockwise as seen from the right, D++ and D-- turn all
+// but U the same way as seen from below, and U and U' turn U. The moves are returned as turns of the faces
+// held still, so R++ starts as L2 and D++ as U2, and later moves turn the faces that have been brought to
+// their places
+func ParsePochmann(text string) (puzzle.Sequence, error) {
+	var at [12]int // the face at each place, as the puzzle has been turned
+	for i := range at {
+		at[i] = i
+	}
+	var res puzzle.Sequence
+	for _, tok := range tokens(text) {
+		mv, ok := pochmannMoves[strings.ToUpper(tok.text)]
+		if !ok {
+			return nil, &ParseError{tok.pos, tok.text, "Pochmann moves are R++, R--, D++, D--, U and U'"}
+		}
+		res = append(res, puzzle.Move{Face: at[mv.face], Turns: mv.turns})
+		if !mv.rest {
+			continue
+		}
+		// the rest turns the other way round the face held, taking the face at p to rot[p]
+		rot := rotation(mv.face)
+		for i := 0; i < (5-mv.turns)%5; i++ {
+			var next [12]int
+			for p, face := range at {
+				next[rot[p]] = face
+			}
+			at = next
+		}
+	}
+	return res, nil
+}
+
+// ParseMoves parses moves in Pochmann notation if any of them ends in ++ or --, and in face turn notation
+// otherwise
+func ParseMoves(text string) (puzzle.Sequence, error) {
+	for _, tok := range tokens(text) {
+		if strings.HasSuffix(tok.text, "++") || strings.HasSuffix(tok.text, "--") {
+			return ParsePochmann(text)
+		}
+	}
+	return ParseSequence(text)
+}
+
+// neighbors reports whether faces a and b share an edge
+func neighbors(a, b int) bool {
+	_, err := puzzle.NeighborIndex(a, b)
+	return err == nil
+}
+
+// rotation returns where turning the whole puzzle a fifth clockwise about face takes each place. The faces
+// next to face go where a turn of face takes their stickers, and each face of the ring below them goes
+// between the two faces of that ring it was between
+func rotation(face int) [12]int {
+	var res [12]int
+	for i := range res {
+		res[i] = -1
+	}
+	sources := puzzle.Move{Face: face, Turns: 1}.Sources()
+	for f := range sources {
+		for _, src := range sources[f] {
+			if int(src)/10 != f {
+				res[int(src)/10] = f
+			}
+		}
+	}
+	res[face] = face
+
+	for f := range res {
+		if res[f] != -1 || neighbors(f, face) {
+			continue
+		}
+		var above []int // the faces next to both face and f, none for the face opposite face
+		for n := range res {
+			if neighbors(n, face) && neighbors(n, f) {
+				above = append(above, res[n])
+			}
+		}
+		if len(above) == 0 {
+			res[f] = f
+			continue
+		}
+		for g := range res {
+			if g != face && !neighbors(g, face) && neighbors(g, above[0]) && neighbors(g, above[1]) {
+				res[f] = g
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,145 @@
+package notation
+
+import (
+	"errors"
+	"megaminx/puzzle"
+	"reflect"
+	"testing"
+)
+
+// mv returns the move turning the face called name by turns fifths
+func mv(name string, turns int) puzzle.Move {
+	return puzzle.Move{Face: faceByName(name), Turns: turns}
+}
+
+func TestParsePochmann(t *testing.T) {
+	tests := []struct {
+		text string
+		want puzzle.Sequence
+	}{
+		{"R++", puzzle.Sequence{mv("L", 2)}},
+		{"R--", puzzle.Sequence{mv("L", -2)}},
+		{"D++", puzzle.Sequence{mv("U", 2)}},
+		{"d-- u'", puzzle.Sequence{mv("U", -2), mv("U", -1)}},
+		{"R++ R-- U", puzzle.Sequence{mv("L", 2), mv("L", -2), mv("U", 1)}},
+		// five R++ turn the puzzle all the way round, so U is back on top
+		{"R++ R++ R++ R++ R++ U", puzzle.Sequence{mv("L", 2), mv("L", 2), mv("L", 2), mv("L", 2), mv("L", 2), mv("U", 1)}},
+		{"D++ D++ D++ D++ D++ R++", puzzle.Sequence{mv("U", 2), mv("U", 2), mv("U", 2), mv("U", 2), mv("U", 2), mv("L", 2)}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePochmann(tt.text)
+		if err != nil {
+			t.Errorf("ParsePochmann(%q): %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePochmann(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParsePochmannTurnsPuzzle(t *testing.T) {
+	// after R++ the face held still is L, and U is no longer on top, so U turns another face
+	seq, err := ParsePochmann("R++ U")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq[1].Face == faceByName("U") || seq[1].Face == faceByName("L") {
+		t.Errorf("U after R++ turns %s", puzzle.FaceName(seq[1].Face))
+	}
+	if !neighbors(seq[1].Face, faceByName("L")) {
+		t.Errorf("U after R++ turns %s, which is not next to L", puzzle.FaceName(seq[1].Face))
+	}
+}
+
+func TestParsePochmannErrors(t *testing.T) {
+	tests := []struct {
+		text  string
+		pos   int
+		token string
+	}{
+		{"F", 0, "F"},
+		{"R++ D-- X", 8, "X"},
+		{"R++  R+ D--", 5, "R+"},
+		{"R++,D+++", 4, "D+++"},
+	}
+	for _, tt := range tests {
+		_, err := ParsePochmann(tt.text)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParsePochmann(%q) error = %v, want a *ParseError", tt.text, err)
+			continue
+		}
+		if perr.Pos != tt.pos || perr.Token != tt.token {
+			t.Errorf("ParsePochmann(%q) fails at %d on %q, want %d on %q", tt.text, perr.Pos, perr.Token, tt.pos, tt.token)
+		}
+	}
+}
+
+func TestParseMoves(t *testing.T) {
+	tests := []struct {
+		text string
+		want puzzle.Sequence
+	}{
+		{"R U'", puzzle.Sequence{mv("R", 1), mv("U", -1)}},
+		{"U' R++", puzzle.Sequence{mv("U", -1), mv("L", 2)}},
+		{"DBR2' dl", puzzle.Sequence{mv("DBR", -2), mv("DL", 1)}},
+		{"R\tU'\n F2\n", puzzle.Sequence{mv("R", 1), mv("U", -1), mv("F", 2)}}, // as pasted
+	}
+	for _, tt := range tests {
+		got, err := ParseMoves(tt.text)
+		if err != nil {
+			t.Errorf("ParseMoves(%q): %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMoves(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+	// a Pochmann move anywhere makes the whole text Pochmann, where R is not a move
+	var perr *ParseError
+	if _, err := ParseMoves("R D++"); !errors.As(err, &perr) || perr.Pos != 0 {
+		t.Errorf("ParseMoves(%q) error = %v, want one at position 0", "R D++", err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	for face := 0; face < 12; face++ {
+		rot := rotation(face)
+		var seen [12]bool
+		for p, q := range rot {
+			if q < 0 || seen[q] {
+				t.Fatalf("rotation(%s) = %v is not a permutation", puzzle.FaceName(face), rot)
+			}
+			seen[q] = true
+			if neighbors(p, face) != neighbors(q, face) {
+				t.Errorf("rotation(%s) takes %s to %s", puzzle.FaceName(face), puzzle.FaceName(p), puzzle.FaceName(q))
+			}
+		}
+		// five fifths are a whole turn
+		for p := range rot {
+			q := p
+			for i := 0; i < 5; i++ {
+				q = rot[q]
+			}
+			if q != p {
+				t.Errorf("five rotations about %s take %s to %s", puzzle.FaceName(face), puzzle.FaceName(p), puzzle.FaceName(q))
+			}
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	s := puzzle.NewState()
+	puzzle.Sequence{mv("R", 1), mv("DBL", -2), mv("F", 2)}.Apply(&s)
+	for _, text := range []string{"R DBL2' F2", FormatState(&s)} {
+		got, err := ParsePosition(text)
+		if err != nil {
+			t.Errorf("ParsePosition(%q): %v", text, err)
+			continue
+		}
+		if got != s {
+			t.Errorf("ParsePosition(%q) is not the state of R DBL2' F2", text)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// This file contains Move and Sequence, the turns solvers produce and the
+// notation used to print them.
+
+package puzzle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// faceNames are the letters of the faces in face turn notation, indexed by face. With white on top,
+// blue in front and yellow on the right, the faces are named by where they sit on the puzzle
+var faceNames = [12]string{
+	"U",   // white
+	"F",   // blue
+	"R",   // yellow
+	"BR",  // purple
+	"BL",  // green
+	"L",   // red
+	"D",   // gray
+	"B",   // cyan
+	"DBR", // orange
+	"DR",  // lime green
+	"DL",  // pink
+	"DBL", // vanilla
+}
+
+// faceByName maps the face names of faceNames back to faces
+var faceByName = make(map[string]int)
+
+func init() {
+	for face, name := range faceNames {
+		faceByName[name] = face
+	}
+}
+
+// FaceName returns the name of face in face turn notation, e.g. "DBR"
+func FaceName(face int) string {
+	if face < 0 || face >= 12 {
+		return fmt.Sprintf("face %d", face)
+	}
+	return faceNames[face]
+}
+
+// FaceByName returns the face called name in face turn notation, if there is one
+func FaceByName(name string) (int, bool) {
+	face, ok := faceByName[name]
+	return face, ok
+}
+
+// Move is a turn of Face by Turns fifths of a revolution, clockwise when Turns is positive
+type Move struct {
+	Face  int
+	Turns int
+}
+
+// Normalize returns the number of fifths of mv in the range -2 to 2
+func (mv Move) Normalize() Move {
+	t := ((mv.Turns % 5) + 5) % 5
+	if t > 2 {
+		t -= 5
+	}
+	return Move{mv.Face, t}
+}
+
+// Validate returns an error if the face of mv does not exist
+func (mv Move) Validate() error {
+	return checkFace(mv.Face)
+}
+
+// Apply turns the face of mv on s, or returns an error and leaves s alone if there is no such face
+func (mv Move) Apply(s *State) error {
+	if err := mv.Validate(); err != nil {
+		return err
+	}
+	mv = mv.Normalize()
+	for i := 0; i < mv.Turns; i++ {
+		s.CW(mv.Face)
+	}
+	for i := 0; i > mv.Turns; i-- {
+		s.CCW(mv.Face)
+	}
+	return nil
+}
+
+// Turn turns face by turns fifths of a revolution, clockwise when turns is positive, or returns an error if
+// there is no such face
+func (s *State) Turn(face, turns int) error {
+	return Move{face, turns}.Apply(s)
+}
+
+// Inverse returns the move that undoes mv
+func (mv Move) Inverse() Move {
+	return Move{mv.Face, -mv.Turns}
+}
+
+// Sources returns where every sticker is moved from by mv: the sticker at face f, tile t after mv was at
+// face Sources()[f][t] / 10, tile Sources()[f][t] % 10 before it. A move of a face that does not exist
+// moves nothing
+func (mv Move) Sources() State {
+	var labels State
+	for f := range labels {
+		for t := range labels[f] {
+			labels[f][t] = byte(f*10 + t)
+		}
+	}
+	mv.Apply(&labels)
+	return labels
+}
+
+// String returns mv in face turn notation, e.g. R, R', R2 and R2', or Move(face, turns) if there is no
+// such face
+func (mv Move) String() string {
+	if mv.Validate() != nil {
+		return fmt.Sprintf("Move(%d, %d)", mv.Face, mv.Turns)
+	}
+	mv = mv.Normalize()
+	name := faceNames[mv.Face]
+	switch mv.Turns {
+	case 1:
+		return name
+	case -1:
+		return name + "'"
+	case 2:
+		return name + "2"
+	case -2:
+		return name + "2'"
+	}
+	return ""
+}
+
+// Sequence is a list of moves applied in order
+type Sequence []Move
+
+// Validate returns an error naming the first move of seq turning a face that does not exist
+func (seq Sequence) Validate() error {
+	for i, mv := range seq {
+		if err := mv.Validate(); err != nil {
+			return fmt.Errorf("move %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Apply applies every move of seq to s, or returns the error of Validate and leaves s alone
+func (seq Sequence) Apply(s *State) error {
+	if err := seq.Validate(); err != nil {
+		return err
+	}
+	for _, mv := range seq {
+		mv.Apply(s)
+	}
+	return nil
+}
+
+// ApplyPieces applies every move of seq to p, or returns the error of Validate and leaves p alone
+func (seq Sequence) ApplyPieces(p *Pieces) error {
+	if err := seq.Validate(); err != nil {
+		return err
+	}
+	for _, mv := range seq {
+		p.Turn(mv.Face, mv.Turns)
+	}
+	return nil
+}
+
+// Inverse returns the sequence that undoes seq
+func (seq Sequence) Inverse() Sequence {
+	res := make(Sequence, len(seq))
+	for i, mv := range seq {
+		res[len(seq)-1-i] = mv.Inverse()
+	}
+	return res
+}
+
+// String returns seq in face turn notation with moves separated by spaces
+func (seq Sequence) String() string {
+	res := make([]string, len(seq))
+	for i, mv := range seq {
+		res[i] = mv.String()
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,67 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMissingFaces(t *testing.T) {
+	for _, face := range []int{-1, 12, 100} {
+		s := NewState()
+		if err := s.CW(face); err == nil {
+			t.Errorf("CW(%d) did not fail", face)
+		}
+		if err := s.CCW(face); err == nil {
+			t.Errorf("CCW(%d) did not fail", face)
+		}
+		if err := (Move{face, 1}).Apply(&s); err == nil {
+			t.Errorf("Apply() of a turn of face %d did not fail", face)
+		}
+		if err := s.Turn(face, 2); err == nil {
+			t.Errorf("Turn(%d) did not fail", face)
+		}
+		if _, err := Neighbors(face); err == nil {
+			t.Errorf("Neighbors(%d) did not fail", face)
+		}
+		if _, err := FaceTurn(face); err == nil {
+			t.Errorf("FaceTurn(%d) did not fail", face)
+		}
+		if _, err := MoveEffect(Move{face, 1}); err == nil {
+			t.Errorf("MoveEffect() of a turn of face %d did not fail", face)
+		}
+		p := NewPieces()
+		if err := p.Turn(face, 1); err == nil {
+			t.Errorf("Pieces.Turn(%d) did not fail", face)
+		}
+		if s != NewState() || !p.Solved() {
+			t.Errorf("turning face %d changed the puzzle", face)
+		}
+		if got := (Move{face, 1}).String(); !strings.HasPrefix(got, "Move(") {
+			t.Errorf("String() of a turn of face %d = %q", face, got)
+		}
+	}
+}
+
+func TestSequenceApplyIsAllOrNothing(t *testing.T) {
+	seq := Sequence{{0, 1}, {2, -1}, {12, 1}, {3, 2}}
+	s := NewState()
+	err := seq.Apply(&s)
+	if err == nil || !strings.Contains(err.Error(), "move 3") {
+		t.Errorf("Apply() = %v, want an error about move 3", err)
+	}
+	if s != NewState() {
+		t.Error("Apply() turned faces before failing")
+	}
+	p := NewPieces()
+	if err := seq.ApplyPieces(&p); err == nil || !p.Solved() {
+		t.Errorf("ApplyPieces() = %v, turning faces %v", err, !p.Solved())
+	}
+
+	seq = seq[:2]
+	if err := seq.Apply(&s); err != nil {
+		t.Fatal(err)
+	}
+	if err := seq.Inverse().Apply(&s); err != nil || s != NewState() {
+		t.Errorf("the inverse did not undo %s: %v", seq, err)
+	}
+}
@@ -0,0 +1,461 @@
+// This file contains the piece model of the megaminx. The 120 stickers of a
+// State belong to 20 corners and 30 edges, and the piece model tracks which
+// piece sits in each position and how it is twisted, rather than the color of
+// every sticker.
+
+package puzzle
+
+import (
+	"fmt"
+)
+
+// NumCorners and NumEdges are the numbers of corner and edge pieces
+const (
+	NumCorners = 20
+	NumEdges   = 30
+)
+
+// Facelet identifies one sticker of a State, Tile is the index into s[Face]
+type Facelet struct {
+	Face int
+	Tile int
+}
+
+// cornerFacelets lists the three stickers of each corner position, starting on its lowest numbered face
+// and going around the corner in the same direction for every corner
+var cornerFacelets [NumCorners][3]Facelet
+
+// edgeFacelets lists the two stickers of each edge position, starting on its lowest numbered face
+var edgeFacelets [NumEdges][2]Facelet
+
+// cornerAt and edgeAt map a sticker back to the position it is part of
+var (
+	cornerAt = make(map[Facelet]int)
+	edgeAt   = make(map[Facelet]int)
+)
+
+// Pieces describes a megaminx by the position and orientation of its pieces. CornerPerm[i] is the corner in
+// position i and CornerOri[i] how many times it is twisted, likewise for edges
+type Pieces struct {
+	CornerPerm [NumCorners]byte
+	CornerOri  [NumCorners]byte // 0, 1 or 2
+	EdgePerm   [NumEdges]byte
+	EdgeOri    [NumEdges]byte // 0 or 1
+}
+
+// PieceMove is a turn, or any sequence of turns, expressed on Pieces: the piece moved into position i comes
+// from position CP[i] (or EP[i]) and gains CO[i] (or EO[i]) twists on the way
+type PieceMove struct {
+	CP [NumCorners]byte
+	CO [NumCorners]byte
+	EP [NumEdges]byte
+	EO [NumEdges]byte
+}
+
+// pieceMoves holds the clockwise turn of each face as a PieceMove, so turns on Pieces are table lookups
+var pieceMoves [12]PieceMove
+
+func init() {
+	initFacelets()
+	for face := 0; face < 12; face++ {
+		pieceMoves[face] = newPieceMove(face)
+	}
+}
+
+// initFacelets numbers the corner and edge positions using the adjacency array m
+func initFacelets() {
+	c, e := 0, 0
+	for f := 0; f < 12; f++ {
+		for k := 0; k < 5; k++ {
+			next := m[f][k]
+			if f < next { // tile 2k+1 is the edge between f and its k-th neighbor
+				edgeFacelets[e] = [2]Facelet{{f, 2*k + 1}, {next, 2*outer[f][k] + 1}}
+				e++
+			}
+			prev := m[f][(k+4)%5]
+			if f < prev && f < next { // tile 2k is the corner between f and its (k-1)-th and k-th neighbors
+				nt, _ := CornerTile(next, f, prev) // the three faces always meet
+				pt, _ := CornerTile(prev, f, next)
+				cornerFacelets[c] = [3]Facelet{{f, 2 * k}, {next, nt}, {prev, pt}}
+				c++
+			}
+		}
+	}
+
+	for i, fs := range cornerFacelets {
+		for _, fl := range fs {
+			cornerAt[fl] = i
+		}
+	}
+	for i, fs := range edgeFacelets {
+		for _, fl := range fs {
+			edgeAt[fl] = i
+		}
+	}
+}
+
+// CornerTile returns the tile on face f of the corner f shares with faces a and b, or an error if the three
+// faces do not meet at a corner
+func CornerTile(f, a, b int) (int, error) {
+	if f < 0 || f >= 12 {
+		return 0, fmt.Errorf("no face %d", f)
+	}
+	for k := 0; k < 5; k++ {
+		prev, next := m[f][(k+4)%5], m[f][k]
+		if (prev == a && next == b) || (prev == b && next == a) {
+			return 2 * k, nil
+		}
+	}
+	return 0, fmt.Errorf("faces %s, %s and %s do not share a corner", FaceName(f), FaceName(a), FaceName(b))
+}
+
+// CornerFacelets returns the three stickers of each corner position, starting on its lowest numbered face
+// and going around the corner in the same direction for every corner
+func CornerFacelets() [NumCorners][3]Facelet {
+	return cornerFacelets
+}
+
+// EdgeFacelets returns the two stickers of each edge position, starting on its lowest numbered face
+func EdgeFacelets() [NumEdges][2]Facelet {
+	return edgeFacelets
+}
+
+// CornerAt and EdgeAt return the position the sticker fl is part of, or -1 if it is not part of a corner or
+// an edge
+func CornerAt(fl Facelet) int {
+	if i, ok := cornerAt[fl]; ok {
+		return i
+	}
+	return -1
+}
+
+func EdgeAt(fl Facelet) int {
+	if i, ok := edgeAt[fl]; ok {
+		return i
+	}
+	return -1
+}
+
+// EdgeBetween and CornerBetween return the position between the given faces, or an error if they do not
+// meet at an edge or a corner
+func EdgeBetween(a, b int) (int, error) {
+	k, err := NeighborIndex(b, a)
+	if err != nil {
+		return 0, err
+	}
+	return edgeAt[Facelet{a, 2*k + 1}], nil
+}
+
+func CornerBetween(a, b, c int) (int, error) {
+	tile, err := CornerTile(a, b, c)
+	if err != nil {
+		return 0, err
+	}
+	return cornerAt[Facelet{a, tile}], nil
+}
+
+// newPieceMove derives the clockwise turn of face from the sticker model by following where each sticker goes
+func newPieceMove(face int) PieceMove {
+	var s State
+	for f := 0; f < 12; f++ {
+		for t := 0; t < 10; t++ {
+			s[f][t] = byte(f*10 + t) // label every sticker uniquely
+		}
+	}
+	s.CW(face)
+
+	var pm PieceMove
+	for i, fs := range cornerFacelets {
+		// the sticker now at the first Facelet of position i came from Facelet src of some position
+		label := int(s[fs[0].Face][fs[0].Tile])
+		src := Facelet{label / 10, label % 10}
+		from := cornerAt[src]
+		pm.CP[i] = byte(from)
+		for j, fl := range cornerFacelets[from] {
+			if fl == src {
+				pm.CO[i] = byte((3 - j) % 3)
+			}
+		}
+	}
+	for i, fs := range edgeFacelets {
+		label := int(s[fs[0].Face][fs[0].Tile])
+		src := Facelet{label / 10, label % 10}
+		from := edgeAt[src]
+		pm.EP[i] = byte(from)
+		if edgeFacelets[from][1] == src {
+			pm.EO[i] = 1
+		}
+	}
+	return pm
+}
+
+// NewPieces returns the pieces of the solved state
+func NewPieces() Pieces {
+	var p Pieces
+	for i := range p.CornerPerm {
+		p.CornerPerm[i] = byte(i)
+	}
+	for i := range p.EdgePerm {
+		p.EdgePerm[i] = byte(i)
+	}
+	return p
+}
+
+// Apply performs pm on p
+func (p *Pieces) Apply(pm *PieceMove) {
+	old := *p
+	for i := range p.CornerPerm {
+		p.CornerPerm[i] = old.CornerPerm[pm.CP[i]]
+		p.CornerOri[i] = (old.CornerOri[pm.CP[i]] + pm.CO[i]) % 3
+	}
+	for i := range p.EdgePerm {
+		p.EdgePerm[i] = old.EdgePerm[pm.EP[i]]
+		p.EdgeOri[i] = old.EdgeOri[pm.EP[i]] ^ pm.EO[i]
+	}
+}
+
+// Turn turns face by turns fifths of a revolution, clockwise when turns is positive, or returns an error if
+// there is no such face
+func (p *Pieces) Turn(face, turns int) error {
+	if err := checkFace(face); err != nil {
+		return err
+	}
+	for i := 0; i < ((turns%5)+5)%5; i++ {
+		p.Apply(&pieceMoves[face])
+	}
+	return nil
+}
+
+// FaceTurn returns the clockwise turn of face as a PieceMove, or an error if there is no such face
+func FaceTurn(face int) (PieceMove, error) {
+	if err := checkFace(face); err != nil {
+		return PieceMove{}, err
+	}
+	return pieceMoves[face], nil
+}
+
+// IdentityMove returns the effect of doing nothing
+func IdentityMove() PieceMove {
+	var pm PieceMove
+	for i := range pm.CP {
+		pm.CP[i] = byte(i)
+	}
+	for i := range pm.EP {
+		pm.EP[i] = byte(i)
+	}
+	return pm
+}
+
+// Compose returns the effect of a followed by b
+func Compose(a, b *PieceMove) PieceMove {
+	var c PieceMove
+	for i := range c.CP {
+		c.CP[i] = a.CP[b.CP[i]]
+		c.CO[i] = (a.CO[b.CP[i]] + b.CO[i]) % 3
+	}
+	for i := range c.EP {
+		c.EP[i] = a.EP[b.EP[i]]
+		c.EO[i] = a.EO[b.EP[i]] ^ b.EO[i]
+	}
+	return c
+}
+
+// Inverse returns the effect that undoes a
+func (a *PieceMove) Inverse() PieceMove {
+	var inv PieceMove
+	for i := range a.CP {
+		inv.CP[a.CP[i]] = byte(i)
+		inv.CO[a.CP[i]] = (3 - a.CO[i]) % 3
+	}
+	for i := range a.EP {
+		inv.EP[a.EP[i]] = byte(i)
+		inv.EO[a.EP[i]] = a.EO[i]
+	}
+	return inv
+}
+
+// MoveEffect returns the effect of mv on the pieces, or an error if there is no such face
+func MoveEffect(mv Move) (PieceMove, error) {
+	if err := mv.Validate(); err != nil {
+		return PieceMove{}, err
+	}
+	pm := IdentityMove()
+	for i := 0; i < ((mv.Turns%5)+5)%5; i++ {
+		pm = Compose(&pm, &pieceMoves[mv.Face])
+	}
+	return pm, nil
+}
+
+// Solved reports whether every piece is in its home position with no twist
+func (p *Pieces) Solved() bool {
+	return *p == NewPieces()
+}
+
+// cornerSolved and edgeSolved report whether the piece belonging in position i is there and untwisted
+func (p *Pieces) cornerSolved(i int) bool {
+	return p.CornerPerm[i] == byte(i) && p.CornerOri[i] == 0
+}
+
+func (p *Pieces) edgeSolved(i int) bool {
+	return p.EdgePerm[i] == byte(i) && p.EdgeOri[i] == 0
+}
+
+// State returns the sticker representation of p
+func (p Pieces) State() State {
+	var s State
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 10; j++ {
+			s[i][j] = byte(i)
+		}
+	}
+	for i, fs := range cornerFacelets {
+		home := cornerFacelets[p.CornerPerm[i]]
+		for j := range fs {
+			// the sticker at Facelet j of position i is Facelet j-ori of the piece
+			s[fs[j].Face][fs[j].Tile] = byte(home[(j+3-int(p.CornerOri[i]))%3].Face)
+		}
+	}
+	for i, fs := range edgeFacelets {
+		home := edgeFacelets[p.EdgePerm[i]]
+		for j := range fs {
+			s[fs[j].Face][fs[j].Tile] = byte(home[j^int(p.EdgeOri[i])].Face)
+		}
+	}
+	return s
+}
+
+// Pieces returns the piece representation of s, or an error describing why s is not a reachable state
+func (s *State) Pieces() (Pieces, error) {
+	var p Pieces
+	var seenCorner [NumCorners]bool
+	var seenEdge [NumEdges]bool
+
+	for i, fs := range cornerFacelets {
+		var colors [3]int
+		for j, fl := range fs {
+			colors[j] = int(s[fl.Face][fl.Tile])
+		}
+		piece, ori, ok := findCorner(colors)
+		if !ok {
+			return Pieces{}, fmt.Errorf("corner %s has colors %s, which no corner has", CornerName(i), colorNames(colors[:]))
+		}
+		if seenCorner[piece] {
+			return Pieces{}, fmt.Errorf("corner %s appears twice", CornerName(piece))
+		}
+		seenCorner[piece] = true
+		p.CornerPerm[i] = byte(piece)
+		p.CornerOri[i] = byte(ori)
+	}
+
+	for i, fs := range edgeFacelets {
+		colors := [2]int{int(s[fs[0].Face][fs[0].Tile]), int(s[fs[1].Face][fs[1].Tile])}
+		piece, ori, ok := findEdge(colors)
+		if !ok {
+			return Pieces{}, fmt.Errorf("edge %s has colors %s, which no edge has", EdgeName(i), colorNames(colors[:]))
+		}
+		if seenEdge[piece] {
+			return Pieces{}, fmt.Errorf("edge %s appears twice", EdgeName(piece))
+		}
+		seenEdge[piece] = true
+		p.EdgePerm[i] = byte(piece)
+		p.EdgeOri[i] = byte(ori)
+	}
+
+	if err := p.Validate(); err != nil {
+		return Pieces{}, err
+	}
+	return p, nil
+}
+
+// findCorner returns the corner whose stickers, read in Facelet order, are colors and how it is twisted.
+// A corner whose colors are mirrored is not found
+func findCorner(colors [3]int) (int, int, bool) {
+	for piece, fs := range cornerFacelets {
+		for ori := 0; ori < 3; ori++ {
+			if colors[ori] == fs[0].Face && colors[(ori+1)%3] == fs[1].Face && colors[(ori+2)%3] == fs[2].Face {
+				return piece, ori, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// findEdge returns the edge whose stickers are colors and whether it is flipped
+func findEdge(colors [2]int) (int, int, bool) {
+	for piece, fs := range edgeFacelets {
+		if colors[0] == fs[0].Face && colors[1] == fs[1].Face {
+			return piece, 0, true
+		}
+		if colors[1] == fs[0].Face && colors[0] == fs[1].Face {
+			return piece, 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// Validate returns an error if p can not be reached from the solved state by turning faces.
+// Every turn is a 5-cycle of corners and of edges, so both permutations are even, and turns preserve the
+// total twist of the corners and the total flip of the edges
+func (p *Pieces) Validate() error {
+	twist, flip := 0, 0
+	for _, o := range p.CornerOri {
+		twist += int(o)
+	}
+	for _, o := range p.EdgeOri {
+		flip += int(o)
+	}
+	if twist%3 != 0 {
+		return fmt.Errorf("corners are twisted: total twist is %d, should be a multiple of 3", twist)
+	}
+	if flip%2 != 0 {
+		return fmt.Errorf("an edge is flipped: an odd number of edges (%d) are flipped", flip)
+	}
+	if odd(p.CornerPerm[:]) {
+		return fmt.Errorf("two corners are swapped: corner permutation is odd")
+	}
+	if odd(p.EdgePerm[:]) {
+		return fmt.Errorf("two edges are swapped: edge permutation is odd")
+	}
+	return nil
+}
+
+// odd reports whether perm is an odd permutation
+func odd(perm []byte) bool {
+	visited := make([]bool, len(perm))
+	parity := false
+	for i := range perm {
+		if visited[i] {
+			continue
+		}
+		// a cycle of length n is n-1 transpositions
+		for j := i; !visited[j]; j = int(perm[j]) {
+			visited[j] = true
+			parity = !parity
+		}
+		parity = !parity
+	}
+	return parity
+}
+
+// CornerName and EdgeName name a position by the faces it sits between, e.g. "U-F-R"
+func CornerName(i int) string {
+	fs := cornerFacelets[i]
+	return faceNames[fs[0].Face] + "-" + faceNames[fs[1].Face] + "-" + faceNames[fs[2].Face]
+}
+
+func EdgeName(i int) string {
+	fs := edgeFacelets[i]
+	return faceNames[fs[0].Face] + "-" + faceNames[fs[1].Face]
+}
+
+// colorNames lists the names of the face colors in colors
+func colorNames(colors []int) string {
+	res := ""
+	for i, c := range colors {
+		if i > 0 {
+			res += "/"
+		}
+		res += ColorName(c)
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package puzzle
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(p *Pieces)
+		err    string // part of the error expected, "" for none
+	}{
+		{"solved", func(p *Pieces) {}, ""},
+		{"twisted corner", func(p *Pieces) { p.CornerOri[3] = 1 }, "corners are twisted"},
+		{"two twisted corners", func(p *Pieces) { p.CornerOri[3], p.CornerOri[7] = 1, 2 }, ""},
+		{"flipped edge", func(p *Pieces) { p.EdgeOri[5] = 1 }, "edge is flipped"},
+		{"two flipped edges", func(p *Pieces) { p.EdgeOri[5], p.EdgeOri[9] = 1, 1 }, ""},
+		{"swapped corners", func(p *Pieces) { p.CornerPerm[0], p.CornerPerm[1] = p.CornerPerm[1], p.CornerPerm[0] }, "two corners are swapped"},
+		{"swapped edges", func(p *Pieces) { p.EdgePerm[0], p.EdgePerm[1] = p.EdgePerm[1], p.EdgePerm[0] }, "two edges are swapped"},
+		{"corner 3-cycle", func(p *Pieces) { p.CornerPerm[0], p.CornerPerm[1], p.CornerPerm[2] = 1, 2, 0 }, ""},
+		{"swapped corners and edges", func(p *Pieces) {
+			p.CornerPerm[0], p.CornerPerm[1] = p.CornerPerm[1], p.CornerPerm[0]
+			p.EdgePerm[0], p.EdgePerm[1] = p.EdgePerm[1], p.EdgePerm[0]
+		}, "two corners are swapped"},
+	}
+	for _, tt := range tests {
+		p := NewPieces()
+		tt.change(&p)
+		err := p.Validate()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: Validate() = %v, want nil", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: Validate() = %v, want an error saying %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestPiecesOfTurnedStates(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		s := NewState()
+		s.Scramble(ScrambleMoves, rng)
+		p, err := s.Pieces()
+		if err != nil {
+			t.Fatalf("Pieces() of a scrambled state: %v", err)
+		}
+		if back := p.State(); back != s {
+			t.Fatalf("State() of the pieces of a scrambled state is another state")
+		}
+	}
+}
+
+func TestTurnsAreValid(t *testing.T) {
+	for face := 0; face < 12; face++ {
+		s := NewState()
+		Move{Face: face, Turns: 1}.Apply(&s)
+		if _, err := s.Pieces(); err != nil {
+			t.Errorf("turning %s: %v", FaceName(face), err)
+		}
+		for i := 0; i < 4; i++ {
+			Move{Face: face, Turns: 1}.Apply(&s)
+		}
+		if s != NewState() {
+			t.Errorf("five turns of %s do not solve the puzzle", FaceName(face))
+		}
+	}
+}
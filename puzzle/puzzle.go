@@ -0,0 +1,252 @@
+// Package puzzle models the megaminx: the stickers of its 12 faces, turns of
+// the faces, and the corner and edge pieces the stickers belong to.
+package puzzle
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// colorName names the color of each face, see ColorName
+var colorName = [12]string{
+	"white", "blue", "yellow", "purple", "green", "red",
+	"gray", "cyan", "orange", "lime green", "pink", "vanilla",
+}
+
+// State contains all state needed to specify a Megaminx; the tile colors of each of the 12 faces
+type State [12][10]byte
+
+// NewState generates the start state
+func NewState() State {
+	var s State
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 10; j++ {
+			s[i][j] = byte(i)
+		}
+	}
+	return s
+}
+
+func CopyState(s State) State {
+	var n State
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 10; j++ {
+			n[i][j] = s[i][j]
+		}
+	}
+	return n
+}
+
+// ColorName returns the name of the color of face, e.g. "white"
+func ColorName(face int) string {
+	if face < 0 || face >= 12 {
+		return fmt.Sprintf("color %d", face)
+	}
+	return colorName[face]
+}
+
+// 2D adjacency array for megaminx
+var m = [12][5]int{
+	{1, 2, 3, 4, 5},   // white
+	{0, 5, 10, 9, 2},  // blue
+	{0, 1, 9, 8, 3},   // yellow
+	{0, 2, 8, 7, 4},   // purple
+	{0, 3, 7, 11, 5},  // green
+	{0, 4, 11, 10, 1}, // red
+	{7, 8, 9, 10, 11}, // gray
+	{6, 11, 4, 3, 8},  // cyan
+	{6, 7, 3, 2, 9},   // orange
+	{6, 8, 2, 1, 10},  // lime green
+	{6, 9, 1, 5, 11},  // pink
+	{6, 10, 5, 4, 7},  // vanilla
+}
+
+// outer[f][k] is the index of face f in the adjacency array of its k-th neighbor m[f][k]. It is set by a
+// variable initializer rather than init, so the init functions of the other files can already turn faces
+var outer = outerIndices()
+
+// outerIndices computes outer
+func outerIndices() [12][5]int {
+	var res [12][5]int
+	for f := 0; f < 12; f++ {
+		for k, n := range m[f] {
+			for i, e := range m[n] {
+				if e == f {
+					res[f][k] = i
+				}
+			}
+		}
+	}
+	return res
+}
+
+// checkFace returns an error if there is no face numbered face
+func checkFace(face int) error {
+	if face < 0 || face >= 12 {
+		return fmt.Errorf("no face %d", face)
+	}
+	return nil
+}
+
+// Neighbors returns the five faces around face, going clockwise, or an error if there is no such face
+func Neighbors(face int) ([5]int, error) {
+	if err := checkFace(face); err != nil {
+		return [5]int{}, err
+	}
+	return m[face], nil
+}
+
+// CCW turns face a fifth of a revolution counter-clockwise, or returns an error if there is no such face.
+// For a better commented and similar explanation, look at CW
+func (s *State) CCW(face int) error {
+	if err := checkFace(face); err != nil {
+		return err
+	}
+	// shift tiles on face
+	for i := 0; i < 2; i++ {
+		s.shiftTilesRight(face)
+	}
+
+	adjFace := m[face][4] // get last adjacent face
+	adjRow := outer[face][4]
+
+	endTileColors := make([]byte, 3)
+	for i := 0; i < 3; i++ {
+		endTileColors[i] = s[adjFace][((adjRow*2)+i)%10]
+	}
+	var endColors [3]byte
+	copy(endColors[:], endTileColors)
+
+	for i := 4; i > 0; i-- {
+		prevFace := m[face][i-1]
+		prevAdjRow := outer[face][i-1]
+		prevTileColors := make([]byte, 3)
+		for j := 0; j < 3; j++ {
+			prevTileColors[j] = s[prevFace][((prevAdjRow*2)+j)%10]
+		}
+
+		curFace := m[face][i]
+		curAdjRow := outer[face][i]
+		for j := 0; j < 3; j++ {
+			s[curFace][((curAdjRow*2)+j)%10] = prevTileColors[j]
+		}
+	}
+	startFace := m[face][0]
+	startAdjRow := outer[face][0]
+	for i := 0; i < 3; i++ {
+		s[startFace][((startAdjRow*2)+i)%10] = endColors[i]
+	}
+	return nil
+}
+
+// CW turns face a fifth of a revolution clockwise, or returns an error if there is no such face
+func (s *State) CW(face int) error {
+	if err := checkFace(face); err != nil {
+		return err
+	}
+	// shift tiles on face
+	for i := 0; i < 2; i++ {
+		s.shiftTilesLeft(face)
+	}
+
+	// get first adjacent face color
+	adjFace := m[face][0]
+
+	// find where the current face is in the first adjacent face's adj array
+	adjRow := outer[face][0]
+
+	// create a copy of them
+	endTileColors := make([]byte, 3)
+	for i := 0; i < 3; i++ {
+		endTileColors[i] = s[adjFace][((adjRow*2)+i)%10]
+	}
+	var endColors [3]byte
+	copy(endColors[:], endTileColors)
+
+	for i := 0; i < 4; i++ {
+		// get the next face color in the adjacency array of current face
+		prevFace := m[face][i+1]
+		// find which edge the current face is adjacent to
+		prevAdjRow := outer[face][i+1]
+		prevTileColors := make([]byte, 3)
+		for j := 0; j < 3; j++ {
+			prevTileColors[j] = s[prevFace][((prevAdjRow*2)+j)%10]
+		}
+
+		curFace := m[face][i]
+		curAdjRow := outer[face][i]
+		for j := 0; j < 3; j++ {
+			s[curFace][((curAdjRow*2)+j)%10] = prevTileColors[j]
+		}
+	}
+	endFace := m[face][4]
+	endAdjRow := outer[face][4]
+	for i := 0; i < 3; i++ {
+		s[endFace][((endAdjRow*2)+i)%10] = endColors[i]
+	}
+	return nil
+}
+
+func (s *State) shiftTilesRight(face int) {
+	end := s[face][9]
+	for i := 9; i > 0; i-- {
+		s[face][i] = s[face][i-1]
+	}
+	s[face][0] = end
+}
+
+func (s *State) shiftTilesLeft(face int) {
+	start := s[face][0]
+	for i := 0; i < 9; i++ {
+		s[face][i] = s[face][i+1]
+	}
+	s[face][9] = start
+}
+
+// NeighborIndex returns the index of face f1 in the adjacency array of f2, or an error if the faces are not
+// next to each other
+func NeighborIndex(f1, f2 int) (int, error) {
+	if f2 < 0 || f2 >= 12 {
+		return 0, fmt.Errorf("no face %d", f2)
+	}
+	for i, e := range m[f2] {
+		if e == f1 {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("face %d is not next to face %d", f1, f2)
+}
+
+// ScrambleMoves is the number of random turns used to fully scramble the puzzle
+const ScrambleMoves = 70
+
+// Randomize makes moves random clockwise turns on the faces of the megaminx
+func (s *State) Randomize(moves int) {
+	for i := 0; i < moves; i++ {
+		face := rand.Intn(12)
+		s.CW(face)
+	}
+}
+
+// Scramble makes moves random clockwise turns like Randomize, drawing the faces from rng, and returns them
+func (s *State) Scramble(moves int, rng *rand.Rand) Sequence {
+	seq := make(Sequence, moves)
+	for i := range seq {
+		seq[i] = Move{Face: rng.Intn(12), Turns: 1}
+		s.CW(seq[i].Face)
+	}
+	return seq
+}
+
+// String returns the string representation of s. Used as a key into the map tracking reached nodes
+func (s *State) String() string {
+	var buffer bytes.Buffer
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 10; j++ {
+			buffer.WriteString(strconv.Itoa(int(s[i][j])))
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,62 @@
+package puzzle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPiecesOfUnreachableStates(t *testing.T) {
+	// swapping the stickers of an edge flips it, which no sequence of turns does
+	s := NewState()
+	fs := EdgeFacelets()[0]
+	s[fs[0].Face][fs[0].Tile], s[fs[1].Face][fs[1].Tile] = s[fs[1].Face][fs[1].Tile], s[fs[0].Face][fs[0].Tile]
+	if _, err := s.Pieces(); err == nil || !strings.Contains(err.Error(), "flipped") {
+		t.Errorf("Pieces() of a state with a flipped edge = %v, want a flipped edge error", err)
+	}
+
+	// painting a sticker of a corner makes colors no corner has
+	s = NewState()
+	c := CornerFacelets()[0]
+	s[c[0].Face][c[0].Tile] = s[c[1].Face][c[1].Tile]
+	if _, err := s.Pieces(); err == nil {
+		t.Error("Pieces() of a state with a corner of two stickers of one color succeeded")
+	}
+
+	// swapping two corners keeps every color count but makes an odd permutation
+	p := NewPieces()
+	p.CornerPerm[0], p.CornerPerm[1] = p.CornerPerm[1], p.CornerPerm[0]
+	s = p.State()
+	if _, err := s.Pieces(); err == nil || !strings.Contains(err.Error(), "swapped") {
+		t.Errorf("Pieces() of a state with two corners swapped = %v, want a swap error", err)
+	}
+}
+
+func TestPiecesOfPaintedStates(t *testing.T) {
+	// slips made painting the stickers of a real puzzle in the edit mode
+	c, e := CornerFacelets()[4], EdgeFacelets()[7]
+	tests := []struct {
+		name  string
+		paint func(s *State)
+		err   string
+	}{
+		{"mirrored corner", func(s *State) {
+			s[c[1].Face][c[1].Tile], s[c[2].Face][c[2].Tile] = s[c[2].Face][c[2].Tile], s[c[1].Face][c[1].Tile]
+		}, "which no corner has"},
+		{"edge twice", func(s *State) {
+			o := EdgeFacelets()[8]
+			s[o[0].Face][o[0].Tile], s[o[1].Face][o[1].Tile] = s[e[0].Face][e[0].Tile], s[e[1].Face][e[1].Tile]
+		}, "twice"},
+		{"no such color", func(s *State) { s[e[0].Face][e[0].Tile] = 12 }, "which no edge has"},
+		{"twisted corner", func(s *State) {
+			a, b, d := s[c[0].Face][c[0].Tile], s[c[1].Face][c[1].Tile], s[c[2].Face][c[2].Tile]
+			s[c[0].Face][c[0].Tile], s[c[1].Face][c[1].Tile], s[c[2].Face][c[2].Tile] = d, a, b
+		}, "twisted"},
+	}
+	for _, tt := range tests {
+		s := NewState()
+		tt.paint(&s)
+		if _, err := s.Pieces(); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: Pieces() = %v, want an error saying %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// This file contains the 60 rotations of the dodecahedron. Rotating a scrambled
+// puzzle and recoloring it so the centers have their usual colors again gives a
+// position that takes exactly as many moves to solve, so states can be reduced to
+// a canonical representative of their 60 rotations.
+
+package puzzle
+
+// Symmetry is a rotation of the puzzle: the stickers of face f move to face face[f], with tile t going to
+// tile (t + offset[f]) % 10
+type Symmetry struct {
+	face    [12]int
+	offset  [12]int
+	inverse [12]int // face[inverse[g]] == g
+}
+
+// symmetries holds every rotation, the identity first, see Symmetries
+var symmetries []Symmetry
+
+func init() {
+	for image := 0; image < 12; image++ {
+		for r := 0; r < 5; r++ {
+			if sym, ok := newSymmetry(image, r); ok {
+				symmetries = append(symmetries, sym)
+			}
+		}
+	}
+}
+
+// Symmetries returns the 60 rotations of the puzzle, the identity first
+func Symmetries() []Symmetry {
+	return append([]Symmetry(nil), symmetries...)
+}
+
+// newSymmetry returns the rotation taking face 0 to face image, with the k-th neighbor of face 0 going to
+// the (k+r)-th neighbor of image. The rotation of every other face follows from its neighbors
+func newSymmetry(image, r int) (Symmetry, bool) {
+	var sym Symmetry
+	var known [12]bool
+	sym.face[0], sym.offset[0], known[0] = image, 2*r, true
+	queue := []int{0}
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+		rot := sym.offset[f] / 2
+		for k, n := range m[f] {
+			g := m[sym.face[f]][(k+rot)%5]
+			// neighbor n of f sees f at index outer[f][k], which must go to where g sees face[f]
+			nrot := (outer[sym.face[f]][(k+rot)%5] - outer[f][k] + 5) % 5
+			if known[n] {
+				if sym.face[n] != g || sym.offset[n] != 2*nrot {
+					return Symmetry{}, false
+				}
+				continue
+			}
+			sym.face[n], sym.offset[n], known[n] = g, 2*nrot, true
+			queue = append(queue, n)
+		}
+	}
+	for f, g := range sym.face {
+		sym.inverse[g] = f
+	}
+	return sym, true
+}
+
+// Apply returns s rotated by sym, with every sticker recolored to the face its color's center moved to
+func (sym *Symmetry) Apply(s *State) State {
+	var res State
+	for f := 0; f < 12; f++ {
+		for t := 0; t < 10; t++ {
+			res[sym.face[f]][(t+sym.offset[f])%10] = byte(sym.face[s[f][t]])
+		}
+	}
+	return res
+}
+
+// Moves returns seq with every face mapped through sym, which does to sym.Apply(s) what seq does to s
+func (sym *Symmetry) Moves(seq Sequence) Sequence {
+	res := make(Sequence, len(seq))
+	for i, mv := range seq {
+		res[i] = Move{sym.face[mv.Face], mv.Turns}
+	}
+	return res
+}
+
+// Unmoves undoes Moves, taking a sequence for sym.Apply(s) back to one for s
+func (sym *Symmetry) Unmoves(seq Sequence) Sequence {
+	res := make(Sequence, len(seq))
+	for i, mv := range seq {
+		res[i] = Move{sym.inverse[mv.Face], mv.Turns}
+	}
+	return res
+}
@@ -0,0 +1,169 @@
+// Package render contains the colors of the puzzle and the geometry of the flat
+// net it is drawn as, in plain coordinates so it can be used without a window,
+// and an SVG renderer built on it.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"megaminx/puzzle"
+)
+
+// faceColors gives the color of the stickers of each face, see Color
+var faceColors = map[int]color.RGBA{
+	// top half
+	0: {0xff, 0xff, 0xff, 0xff}, // white
+	1: {0, 0, 0xff, 0xff},       // blue
+	2: {0xff, 0xff, 0, 0xff},    // yellow
+	3: {80, 00, 80, 0xff},       // purple
+	4: {0, 0x64, 0, 0xff},       // green
+	5: {0xff, 0, 0, 0xff},       // red
+
+	// bottom half
+	6:  {80, 80, 80, 0xff},       // gray
+	7:  {00, 0xff, 0xff, 0xff},   // cyan
+	8:  {0xff, 0xa5, 0, 0xff},    // orange
+	9:  {0x65, 0xfe, 0x08, 0xff}, // lime green
+	10: {0xfc, 0x6c, 0x85, 0xff}, // pink
+	11: {0xf3, 0xe5, 0xab, 0xff}, // vanilla
+}
+
+// Color returns the color of the stickers of face, taken modulo 12 like the colors of a puzzle.State
+func Color(face int) color.RGBA {
+	return faceColors[(face%12+12)%12]
+}
+
+// Point is a Point of the net, with y growing downwards as on the screen
+type Point struct {
+	X, Y float64
+}
+
+// FacePolygons returns the outline of a face of radius 2 centered on the origin: the center pentagon,
+// followed by stickers 0 through 9, going around the face from the bottom left corner
+func FacePolygons() [11][]Point {
+	// https://mathworld.wolfram.com/RegularPentagon.html
+	c1 := math.Cos(2 * math.Pi / 5)
+	c2 := math.Cos(math.Pi / 5)
+	s1 := math.Sin(2 * math.Pi / 5)
+	s2 := math.Sin(4 * math.Pi / 5)
+
+	// corner points for the outer pentagon
+	s1p := s1 * 2
+	s2p := s2 * 2
+	c1p := c1 * 2
+	c2p := c2 * 2
+
+	return [11][]Point{
+		// inner pentagon
+		{{-s2, -c2}, {s2, -c2}, {s1, c1}, {0, 1}, {-s1, c1}},
+		// bottom left corner
+		{{-s2p, -c2p}, {-s2p - (-s2p+s1p)*.3, -c2p + (c1p+c2p)*.3}, {-s2 * 1.1, -c2 * 1.1}, {-s2p + (s2p+s2p)*.3, -c2p}},
+		// bottom edge
+		{{-s2p + (s2p+s2p)*.34, -c2p}, {(-s2p + (s2p+s2p)*.34) * -1, -c2p}, {s2 - (s1-s2)*.07, -c2 - (c1+c2)*.07}, {(s2 - (s1-s2)*.07) * -1, -c2 - (c1+c2)*.07}},
+		// bottom right corner
+		{{s2p, -c2p}, {(-s2p - (-s2p+s1p)*.3) * -1, -c2p + (c1p+c2p)*.3}, {s2 * 1.1, -c2 * 1.1}, {(-s2p + (s2p+s2p)*.3) * -1, -c2p}},
+		// right edge
+		{{(-s1p + (-s2p+s1p)*.34) * -1, c1p - (c1p+c2p)*.34}, {(-s2p - (-s2p+s1p)*.34) * -1, -c2p + (c1p+c2p)*.34}, {(-s2 - (2*s2)*.07) * -1, -c2}, {(-s1 - (s1)*.07) * -1, c1 - (1-c1)*.07}},
+		// top right corner
+		{{s1p, c1p}, {(-s1p + (s1p)*.3) * -1, c1p + (2-c1p)*.3}, {(-s1 * 1.1) * -1, c1 * 1.1}, {(-s1p + (-s2p+s1p)*.3) * -1, c1p - (c1p+c2p)*.3}},
+		// top right edge
+		{{(-s1p + (s1p)*.34) * -1, c1p + (2-c1p)*.34}, {(0 + (s1p+0)*-.34) * -1, 2 - (2-c1p)*.34}, {s1 * .07, 1 + (1-c1)*.07}, {(-s1 - (-s2+s1)*.07) * -1, c1 + (c1+c2)*.07}},
+		// top corner
+		{{0, 2}, {0 + (s1p+0)*.3, 2 - (2-c1p)*.3}, {0, 1.1}, {0 + (s1p+0)*-.3, 2 - (2-c1p)*.3}},
+		// top left edge
+		{{-s1p + (s1p)*.34, c1p + (2-c1p)*.34}, {0 + (s1p+0)*-.34, 2 - (2-c1p)*.34}, {s1 * -.07, 1 + (1-c1)*.07}, {-s1 - (-s2+s1)*.07, c1 + (c1+c2)*.07}},
+		// top left corner
+		{{-s1p, c1p}, {-s1p + (s1p)*.3, c1p + (2-c1p)*.3}, {-s1 * 1.1, c1 * 1.1}, {-s1p + (-s2p+s1p)*.3, c1p - (c1p+c2p)*.3}},
+		// left edge
+		{{-s1p + (-s2p+s1p)*.34, c1p - (c1p+c2p)*.34}, {-s2p - (-s2p+s1p)*.34, -c2p + (c1p+c2p)*.34}, {-s2 - (2*s2)*.07, -c2}, {-s1 - (s1)*.07, c1 - (1-c1)*.07}},
+	}
+}
+
+// netSlot places a face in one of the two flowers of the net: the face is rotated by before, reflected
+// over the y axis, rotated by after, and moved by (dx, dy) times the scale from the center of its flower
+type netSlot struct {
+	before, after float64
+	dx, dy        float64
+}
+
+var (
+	middleSlot = netSlot{0, 0, 0, 0}
+	tLeftSlot  = netSlot{math.Pi * 0.2, 0, -1.95, -2.7}
+	tRightSlot = netSlot{math.Pi * -0.2, 0, 1.95, -2.7}
+	bLeftSlot  = netSlot{math.Pi * 0.6, 0, -3.18, 1}
+	bRightSlot = netSlot{0, math.Pi * .6, 3.18, 1}
+	bottomSlot = netSlot{math.Pi, 0, 0, 3.33}
+)
+
+// netSlots gives the slot of each face. Faces 0-5 form the flower on the right, 6-11 the one on the left
+var netSlots = [12]netSlot{
+	middleSlot, bottomSlot, bRightSlot, tRightSlot, tLeftSlot, bLeftSlot,
+	middleSlot, bottomSlot, bRightSlot, tRightSlot, tLeftSlot, bLeftSlot,
+}
+
+// rotate rotates p about the origin by r radians
+func (p Point) rotate(r float64) Point {
+	return Point{p.X*math.Cos(r) - p.Y*math.Sin(r), p.X*math.Sin(r) + p.Y*math.Cos(r)}
+}
+
+// NetPolygons returns the polygons of FacePolygons for every face, placed in a net of the given scale
+// centered in a width by height area
+func NetPolygons(scale, width, height float64) [12][11][]Point {
+	var res [12][11][]Point
+	shape := FacePolygons()
+	for face, slot := range netSlots {
+		flower := 6.0 // the top half is drawn on the right, the bottom half on the left
+		if face >= 6 {
+			flower = -6
+		}
+		for i, poly := range shape {
+			res[face][i] = make([]Point, len(poly))
+			for j, p := range poly {
+				p = Point{p.X * scale, p.Y * scale}.rotate(math.Pi).rotate(slot.before)
+				p.X = -p.X
+				p = p.rotate(slot.after)
+				p.X += width/2 + scale*(slot.dx+flower)
+				p.Y += height/2 + scale*slot.dy
+				res[face][i][j] = p
+			}
+		}
+	}
+	return res
+}
+
+// hexColor returns the color of face in #rrggbb form
+func hexColor(face int) string {
+	c := Color(face)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG draws s as an SVG image of the net, scale pixels to the radius of a face's center pentagon
+func SVG(w io.Writer, s puzzle.State, scale float64) error {
+	width, height := scale*25, scale*10
+	if _, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n",
+		width, height, width, height); err != nil {
+		return err
+	}
+	for face, polys := range NetPolygons(scale, width, height) {
+		for i, poly := range polys {
+			color := face // the center pentagon
+			if i > 0 {
+				color = int(s[face][i-1]) % 12
+			}
+			pts := ""
+			for j, p := range poly {
+				if j > 0 {
+					pts += " "
+				}
+				pts += fmt.Sprintf("%.2f,%.2f", p.X, p.Y)
+			}
+			if _, err := fmt.Fprintf(w, "  <polygon points=\"%s\" fill=\"%s\" stroke=\"black\" stroke-width=\"0.5\"/>\n", pts, hexColor(color)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
@@ -0,0 +1,270 @@
+// This file contains the geometry of the puzzle as a dodecahedron. Each face
+// of a regular dodecahedron gets the stickers of FacePolygons, turned so every
+// edge sticker faces the neighbor the puzzle says it borders, and the solid is
+// projected through an orbiting camera with the faces turned away culled.
+
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"megaminx/puzzle"
+	"sort"
+)
+
+// vec3 is a point or direction in space: x to the right, y up and z towards the viewer
+type vec3 [3]float64
+
+func (a vec3) add(b vec3) vec3 {
+	return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func (a vec3) scale(k float64) vec3 {
+	return vec3{a[0] * k, a[1] * k, a[2] * k}
+}
+
+func (a vec3) dot(b vec3) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func (a vec3) unit() vec3 {
+	return a.scale(1 / math.Sqrt(a.dot(a)))
+}
+
+// rotate rotates a about the unit axis by r radians, counter-clockwise when seen from the tip of axis
+func (a vec3) rotate(axis vec3, r float64) vec3 {
+	cos, sin := math.Cos(r), math.Sin(r)
+	return a.scale(cos).add(axis.cross(a).scale(sin)).add(axis.scale(axis.dot(a) * (1 - cos)))
+}
+
+// faceFrame places the points of FacePolygons on a face of the dodecahedron at center + x*X + y*Y
+type faceFrame struct {
+	normal, center, x, y vec3
+}
+
+// place returns where p of FacePolygons lies on the face
+func (fr *faceFrame) place(p Point) vec3 {
+	return fr.center.add(fr.x.scale(p.X)).add(fr.y.scale(p.Y))
+}
+
+// phi is the golden ratio, which the dodecahedron is made of
+var phi = (1 + math.Sqrt(5)) / 2
+
+// solidRadius is the distance from the center of the dodecahedron to its corners, in the units of
+// FacePolygons: the faces are phi^2 from the center and their corners 2 from the centers of the faces
+var solidRadius = math.Sqrt(phi*phi*phi*phi + 4)
+
+var frames = dodecahedron()
+
+// dodecahedron returns the frame of every face of a dodecahedron whose faces have the radius of
+// FacePolygons, with U on top and F in front
+func dodecahedron() [12]faceFrame {
+	// the face normals of a dodecahedron point at the corners of an icosahedron
+	var normals []vec3
+	for _, a := range []float64{1, -1} {
+		for _, b := range []float64{phi, -phi} {
+			normals = append(normals, vec3{0, a, b}.unit(), vec3{a, b, 0}.unit(), vec3{b, 0, a}.unit())
+		}
+	}
+
+	var assigned [12]int
+	if !assignNormals(normals, assigned[:], 0, make([]bool, 12)) {
+		panic("render: the faces of the puzzle do not fit a dodecahedron")
+	}
+	var n [12]vec3
+	for face, i := range assigned {
+		n[face] = normals[i]
+	}
+
+	// turn the solid so U is up and F is in front
+	up := n[0]
+	front := n[1].add(up.scale(-n[1].dot(up))).unit()
+	right := up.cross(front)
+	for face := range n {
+		n[face] = vec3{n[face].dot(right), n[face].dot(up), n[face].dot(front)}
+	}
+
+	res := faceFrames(n)
+	if turnSign(&res, 0) > 0 { // a clockwise turn must look clockwise from outside; mirror if it does not
+		for face := range n {
+			n[face][0] = -n[face][0]
+		}
+		res = faceFrames(n)
+	}
+	for face := range res {
+		if turnSign(&res, face) > 0 {
+			panic(fmt.Sprintf("render: turns of %s do not match the dodecahedron", puzzle.FaceName(face)))
+		}
+	}
+	return res
+}
+
+// assignNormals assigns normals to the faces from face on, so that faces are neighbors exactly when their
+// normals are, and reports whether it could
+func assignNormals(normals []vec3, assigned []int, face int, used []bool) bool {
+	if face == len(assigned) {
+		return true
+	}
+	for i, n := range normals {
+		if used[i] {
+			continue
+		}
+		fits := true
+		for other := 0; other < face && fits; other++ {
+			_, err := puzzle.NeighborIndex(face, other)
+			fits = (err == nil) == (n.dot(normals[assigned[other]]) > 0.4) // neighbors meet at a dot of 1/sqrt(5)
+		}
+		if !fits {
+			continue
+		}
+		assigned[face], used[i] = i, true
+		if assignNormals(normals, assigned, face+1, used) {
+			return true
+		}
+		used[i] = false
+	}
+	return false
+}
+
+// faceFrames returns the frames of faces with normals n, turning FacePolygons so that the edge sticker
+// between two faces lies towards the other face
+func faceFrames(n [12]vec3) [12]faceFrame {
+	polys := FacePolygons()
+	var edges [12][]puzzle.Facelet // the edge stickers of each face and the faces they border
+	for _, fl := range puzzle.EdgeFacelets() {
+		edges[fl[0].Face] = append(edges[fl[0].Face], puzzle.Facelet{Face: fl[1].Face, Tile: fl[0].Tile})
+		edges[fl[1].Face] = append(edges[fl[1].Face], puzzle.Facelet{Face: fl[0].Face, Tile: fl[1].Tile})
+	}
+
+	var res [12]faceFrame
+	for face, normal := range n {
+		// solve x*d.X + y*d.Y = u for the directions d of two edge stickers on the face and u of the
+		// faces they border
+		var d [2]Point
+		var u [2]vec3
+		for k, e := range edges[face][:2] {
+			c := centroid(polys[1+e.Tile])
+			l := math.Hypot(c.X, c.Y)
+			d[k] = Point{c.X / l, c.Y / l}
+			u[k] = n[e.Face].add(normal.scale(-n[e.Face].dot(normal))).unit()
+		}
+		det := d[0].X*d[1].Y - d[1].X*d[0].Y
+		res[face] = faceFrame{
+			normal: normal,
+			center: normal.scale(phi * phi),
+			x:      u[0].scale(d[1].Y / det).add(u[1].scale(-d[0].Y / det)),
+			y:      u[0].scale(-d[1].X / det).add(u[1].scale(d[0].X / det)),
+		}
+	}
+	return res
+}
+
+// turnSign returns the sign of the angle about the normal of face by which a clockwise turn of face
+// moves its stickers
+func turnSign(frames *[12]faceFrame, face int) float64 {
+	fr := &frames[face]
+	polys := FacePolygons()
+	src := int(puzzle.Move{Face: face, Turns: 1}.Sources()[face][0]) % 10
+	from := fr.place(centroid(polys[1+src])).add(fr.center.scale(-1))
+	to := fr.place(centroid(polys[1])).add(fr.center.scale(-1))
+	return math.Copysign(1, from.cross(to).dot(fr.normal))
+}
+
+// centroid returns the average of the points of poly
+func centroid(poly []Point) Point {
+	var c Point
+	for _, p := range poly {
+		c.X += p.X / float64(len(poly))
+		c.Y += p.Y / float64(len(poly))
+	}
+	return c
+}
+
+// Camera looks at the puzzle from outside, orbiting it by Yaw radians about the vertical axis and then
+// tilting by Pitch radians towards the top
+type Camera struct {
+	Yaw, Pitch float64
+}
+
+// view turns a from the world into the camera's view
+func (c Camera) view(a vec3) vec3 {
+	a = a.rotate(vec3{0, 1, 0}, -c.Yaw)
+	return a.rotate(vec3{1, 0, 0}, c.Pitch)
+}
+
+// Turn is a turn of the puzzle shown part way through
+type Turn struct {
+	Move     puzzle.Move
+	Progress float64 // from 0 before the turn to 1 after it
+}
+
+// Polygon is a sticker or face center as drawn by SolidPolygons
+type Polygon struct {
+	Points []Point
+	Color  color.RGBA // shaded by how much the polygon faces the camera
+	Face   int
+	Tile   int // -1 for the center of the face
+	depth  float64
+}
+
+// SolidPolygons returns the stickers and face centers of s on a dodecahedron seen through cam, the ones
+// facing the camera only, from back to front so later polygons are drawn over earlier ones. The points
+// are centered on the origin with y growing downwards, and scale is the radius of the solid. If turn is
+// not nil, s is the state before it and the turning layer is drawn rotated
+func SolidPolygons(s *puzzle.State, cam Camera, turn *Turn, scale float64) []Polygon {
+	var sources puzzle.State
+	var axis vec3
+	var angle float64
+	if turn != nil {
+		sources = turn.Move.Sources()
+		axis = frames[turn.Move.Face].normal
+		angle = -2 * math.Pi / 5 * float64(turn.Move.Turns) * turn.Progress // clockwise from outside
+	}
+
+	const distance = 4 // distance of the camera in radii of the solid, for perspective
+	var res []Polygon
+	for face := range frames {
+		fr := &frames[face]
+		for i, poly := range FacePolygons() {
+			tile := i - 1
+			normal := fr.normal
+			turning := turn != nil && (face == turn.Move.Face || tile >= 0 && int(sources[face][tile]) != face*10+tile)
+			if turning {
+				normal = normal.rotate(axis, angle)
+			}
+			normal = cam.view(normal)
+			if normal[2] <= 0 {
+				continue
+			}
+
+			p := Polygon{Points: make([]Point, len(poly)), Face: face, Tile: tile}
+			for j, pt := range poly {
+				v := fr.place(pt)
+				if turning {
+					v = v.rotate(axis, angle)
+				}
+				v = cam.view(v).scale(1 / solidRadius)
+				f := distance / (distance - v[2])
+				p.Points[j] = Point{v[0] * f * scale, -v[1] * f * scale}
+				p.depth += v[2] / float64(len(poly))
+			}
+
+			c := Color(face)
+			if tile >= 0 {
+				c = Color(int(s[face][tile]) % 12)
+			}
+			shade := 0.55 + 0.45*normal[2]
+			p.Color = color.RGBA{uint8(float64(c.R) * shade), uint8(float64(c.G) * shade), uint8(float64(c.B) * shade), 0xff}
+			res = append(res, p)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].depth < res[j].depth
+	})
+	return res
+}
@@ -0,0 +1,412 @@
+// This file contains the compact storage used by A* and the other searches
+// whose priorities are small integers. Nodes live in an arena of fixed size
+// chunks and refer to their parent by index, states are packed as the
+// coordinates of their pieces, the frontier is a bucket queue indexed by
+// priority, and reached states are found through an open-addressing table of
+// arena indices. There is one node per state: a state reached again by a
+// shorter path has its node overwritten and pushed again, and the copy left in
+// the frontier is skipped when it comes up with a key the node no longer has.
+
+package solver
+
+import (
+	"math/bits"
+	"megaminx/puzzle"
+)
+
+// packedState is a state as the coordinates of its pieces, 32 bytes: the rank of the corner permutation,
+// the corner twists in base 3 and the edge flips as bits, and the rank of the edge permutation split in
+// two. Ranks are Lehmer codes, the permutation's number in lexicographic order
+type packedState [4]uint64
+
+// edgeSplit is the number of Lehmer digits of the edge permutation in the first of its two words. The
+// digits of the first 14 positions, of radix 30 down to 17, take 63.5 bits and the other 16 take 44.3
+const edgeSplit = 14
+
+// pack returns s packed, or false if its stickers do not make a corner or edge in some position, or make
+// the same piece twice. Such a state can never be solved
+func pack(s *puzzle.State) (packedState, bool) {
+	var p packedState
+	var corners [puzzle.NumCorners]int
+	var edges [puzzle.NumEdges]int
+	ori, pow := uint64(0), uint64(1)
+	for i, fs := range cornerFacelets {
+		a, b, c := s[fs[0].Face][fs[0].Tile], s[fs[1].Face][fs[1].Tile], s[fs[2].Face][fs[2].Tile]
+		if a >= 12 || b >= 12 || c >= 12 {
+			return p, false
+		}
+		v := cornerByColors[a][b][c]
+		if v < 0 {
+			return p, false
+		}
+		corners[i] = int(v / 3)
+		ori += uint64(v%3) * pow
+		pow *= 3
+	}
+	for i, fs := range edgeFacelets {
+		a, b := s[fs[0].Face][fs[0].Tile], s[fs[1].Face][fs[1].Tile]
+		if a >= 12 || b >= 12 {
+			return p, false
+		}
+		v := edgeByColors[a][b]
+		if v < 0 {
+			return p, false
+		}
+		edges[i] = int(v / 2)
+		ori |= uint64(v%2) << (32 + i)
+	}
+	var ok bool
+	if p[0], _, ok = rank(corners[:], puzzle.NumCorners); !ok {
+		return p, false
+	}
+	if p[2], p[3], ok = rank(edges[:], edgeSplit); !ok {
+		return p, false
+	}
+	p[1] = ori
+	return p, true
+}
+
+// rank returns the Lehmer code of perm in mixed radix, the digits of the first split positions in the
+// first word and the others in the second, or false if perm is not a permutation
+func rank(perm []int, split int) (uint64, uint64, bool) {
+	var words [2]uint64
+	var used uint32
+	n := len(perm)
+	for i, v := range perm {
+		if used&(1<<v) != 0 {
+			return 0, 0, false
+		}
+		// the digit is the number of values below v not used yet, and its radix the number left
+		digit := v - bits.OnesCount32(used&(1<<v-1))
+		used |= 1 << v
+		w := 0
+		if i >= split {
+			w = 1
+		}
+		words[w] = words[w]*uint64(n-i) + uint64(digit)
+	}
+	return words[0], words[1], true
+}
+
+// unrank fills perm with the permutation rank returned
+func unrank(perm []int, split int, first, second uint64) {
+	n := len(perm)
+	var digits [puzzle.NumEdges]int
+	for i := n - 1; i >= 0; i-- {
+		w := &second
+		if i < split {
+			w = &first
+		}
+		digits[i] = int(*w % uint64(n-i))
+		*w /= uint64(n - i)
+	}
+	var used uint32
+	for i := range perm {
+		// the value is the digit-th smallest not used yet
+		v, left := 0, digits[i]
+		for ; ; v++ {
+			if used&(1<<v) == 0 {
+				if left == 0 {
+					break
+				}
+				left--
+			}
+		}
+		perm[i] = v
+		used |= 1 << v
+	}
+}
+
+// unpack returns the State p was packed from
+func (p *packedState) unpack() puzzle.State {
+	var pieces puzzle.Pieces
+	var corners [puzzle.NumCorners]int
+	var edges [puzzle.NumEdges]int
+	unrank(corners[:], puzzle.NumCorners, p[0], 0)
+	unrank(edges[:], edgeSplit, p[2], p[3])
+	ori := p[1] & (1<<32 - 1)
+	for i, c := range corners {
+		pieces.CornerPerm[i], pieces.CornerOri[i] = byte(c), byte(ori%3)
+		ori /= 3
+	}
+	for i, e := range edges {
+		pieces.EdgePerm[i], pieces.EdgeOri[i] = byte(e), byte(p[1]>>(32+i)&1)
+	}
+	return pieces.State()
+}
+
+// hash returns a hash of p mixing every bit into the low ones, which stateIndex uses
+func (p *packedState) hash() uint32 {
+	h := p[0]*0x9e3779b97f4a7c15 ^ p[1]*0xc2b2ae3d27d4eb4f ^ p[2]*0x165667b19e3779f9 ^ p[3]*0x27d4eb2f165667c5
+	return uint32(h>>32 ^ h)
+}
+
+// arenaNode is a search node stored in an arena, 40 bytes in total
+type arenaNode struct {
+	state  packedState
+	parent int32 // index of the parent in the arena, -1 for the start node
+	move   int8  // index in SearchOptions.Moves of the turn reaching this node from its parent, -1 for the start node
+	g      uint8
+	h      uint16 // heuristic of the state, kept so it is computed once per node
+}
+
+// chunkBits sets the number of nodes in a chunk of an arena
+const chunkBits = 12
+
+// arena holds every node created by a search in chunks of 1<<chunkBits nodes, so growing a large arena
+// never copies its nodes or leaves a discarded backing array to the garbage collector. Only the first
+// chunk grows by appending, so small searches stay small. A node pointer is valid until the next add
+type arena struct {
+	chunks [][]arenaNode // every chunk but the last is full
+	n      int
+}
+
+// at returns the node at index i
+func (a *arena) at(i int32) *arenaNode {
+	return &a.chunks[i>>chunkBits][i&(1<<chunkBits-1)]
+}
+
+// add appends n to a and returns its index
+func (a *arena) add(n arenaNode) int32 {
+	last := len(a.chunks) - 1
+	if last < 0 || len(a.chunks[last]) == 1<<chunkBits {
+		capacity := 1 << chunkBits
+		if last < 0 {
+			capacity = 1024
+		}
+		a.chunks, last = append(a.chunks, make([]arenaNode, 0, capacity)), last+1
+	}
+	a.chunks[last] = append(a.chunks[last], n)
+	a.n++
+	return int32(a.n - 1)
+}
+
+// stateIndex maps a state to its arena node. Slots hold an arena index plus one, 0 marks an empty slot
+type stateIndex struct {
+	slots []int32
+	n     int
+}
+
+// slot returns the slot of p, which is either empty or holds the node with state p
+func (idx *stateIndex) slot(a *arena, p *packedState) int {
+	mask := len(idx.slots) - 1
+	for i := int(p.hash()) & mask; ; i = (i + 1) & mask { // linear probing
+		if idx.slots[i] == 0 || a.at(idx.slots[i]-1).state == *p {
+			return i
+		}
+	}
+}
+
+// get returns the node with state p, or -1 if p has not been reached
+func (idx *stateIndex) get(a *arena, p *packedState) int32 {
+	if idx.n == 0 {
+		return -1
+	}
+	return idx.slots[idx.slot(a, p)] - 1
+}
+
+// add records node as the one of its state, which has no node yet
+func (idx *stateIndex) add(a *arena, node int32) {
+	if 4*(idx.n+1) > 3*len(idx.slots) { // keep the table at most three quarters full
+		idx.grow(a)
+	}
+	idx.slots[idx.slot(a, &a.at(node).state)] = node + 1
+	idx.n++
+}
+
+// grow doubles the number of slots and reinserts every node
+func (idx *stateIndex) grow(a *arena) {
+	old := idx.slots
+	size := 2 * len(old)
+	if size == 0 {
+		size = 1024
+	}
+	idx.slots = make([]int32, size)
+	for _, v := range old {
+		if v != 0 {
+			idx.slots[idx.slot(a, &a.at(v-1).state)] = v
+		}
+	}
+}
+
+// bucketQueue is a priority queue of arena indices for small integer priorities. Each bucket is a stack,
+// or a queue when fifo is set
+type bucketQueue struct {
+	buckets [][]int32
+	heads   []int // index of the front of each bucket, always 0 unless fifo
+	min     int   // no bucket below min holds an index
+	n       int
+	fifo    bool
+}
+
+// Len returns the number of indices in the queue
+func (q *bucketQueue) Len() int {
+	return q.n
+}
+
+// push inserts node with priority key
+func (q *bucketQueue) push(key int, node int32) {
+	for len(q.buckets) <= key {
+		q.buckets = append(q.buckets, nil)
+		q.heads = append(q.heads, 0)
+	}
+	q.buckets[key] = append(q.buckets[key], node)
+	if key < q.min {
+		q.min = key
+	}
+	q.n++
+}
+
+// pop removes and returns an index with the lowest priority, and that priority
+func (q *bucketQueue) pop() (int32, int) {
+	for q.heads[q.min] == len(q.buckets[q.min]) {
+		q.min++
+	}
+	b := q.buckets[q.min]
+	var node int32
+	if q.fifo {
+		head := q.heads[q.min]
+		node, head = b[head], head+1
+		if 2*head >= len(b) { // at least half popped, so the rest moves to the front and the space is reused
+			q.buckets[q.min], head = b[:copy(b, b[head:])], 0
+		}
+		q.heads[q.min] = head
+	} else {
+		node, q.buckets[q.min] = b[len(b)-1], b[:len(b)-1]
+	}
+	q.n--
+	return node, q.min
+}
+
+// bucket returns the indices in bucket key, front first
+func (q *bucketQueue) bucket(key int) []int32 {
+	return q.buckets[key][q.heads[key]:]
+}
+
+// maxDepth bounds g in the keys of arenaSearch, so PreferDeeper can order nodes of equal f by g
+const maxDepth = 255
+
+// key returns the bucket of a node with cost g and heuristic h
+func (o SearchOptions) key(g, h int) int {
+	var f int
+	switch o.Strategy {
+	case GreedyBestFirst:
+		// h alone ties on most of the frontier, and going deeper first lets greedy wander off for
+		// millions of nodes, so ties go to the shallower node whatever the tie-break
+		return h*(maxDepth+1) + g
+	case UniformCost:
+		f = g
+	default:
+		f = g + h
+	}
+	if o.TieBreak == PreferDeeper {
+		return f*(maxDepth+1) + maxDepth - g
+	}
+	return f
+}
+
+// arenaSearch is bestFirst for strategies whose priority is an integer, storing nodes in an arena
+func arenaSearch(s puzzle.State, opts SearchOptions, stats *Stats, cp *checkpointer, resume *checkpoint) (Node, bool) {
+	var nodes arena
+	var index stateIndex
+	q := bucketQueue{fifo: opts.TieBreak == FIFO}
+	moves := opts.Moves.moves()
+
+	if resume != nil {
+		nodes, q.buckets, q.min = resume.arena(), resume.Buckets, resume.Min
+		q.heads = make([]int, len(q.buckets))
+		for _, b := range q.buckets {
+			q.n += len(b)
+		}
+		for i := 0; i < nodes.n; i++ {
+			index.add(&nodes, int32(i))
+		}
+	} else {
+		p, ok := pack(&s)
+		if !ok {
+			return Node{}, false
+		}
+		h := opts.Heuristic(s)
+		start := nodes.add(arenaNode{state: p, parent: -1, move: -1, h: uint16(h)})
+		index.add(&nodes, start)
+		q.push(opts.key(0, h), start)
+	}
+
+	for q.Len() > 0 {
+		if q.Len() > stats.MaxFrontier {
+			stats.MaxFrontier = q.Len()
+		}
+		top, key := q.pop()
+		node := *nodes.at(top)
+		if opts.key(int(node.g), int(node.h)) != key {
+			continue // a shorter path to this state was found after top was pushed, and pushed again
+		}
+		cur := node.state.unpack()
+		if cur == solvedState {
+			stats.Frontier = q.Len()
+			return nodes.path(top, opts), true
+		}
+		if opts.MaxNodes > 0 && stats.Expanded >= opts.MaxNodes {
+			break
+		}
+		if save, stop := cp.poll(); save || stop {
+			if save { // top has not been expanded yet, so the checkpoint puts it back in the frontier
+				cp.save(func(c *checkpoint) { c.setArena(&nodes, &q, top, key) })
+			}
+			if stop {
+				stats.Stopped = true
+				break
+			}
+		}
+		stats.Expanded++
+		g := int(node.g) + 1
+		if g > maxDepth {
+			continue
+		}
+		for i, mv := range moves {
+			stats.Generated++
+			child := cur
+			mv.Apply(&child)
+			p, _ := pack(&child) // turns keep every piece whole
+			old := index.get(&nodes, &p)
+			if old >= 0 {
+				n := nodes.at(old)
+				if int(n.g) <= g {
+					continue
+				}
+				// overwrite the node, which stays where it is in the frontier under its old key
+				n.parent, n.move, n.g = top, int8(i), uint8(g)
+				q.push(opts.key(g, int(n.h)), old)
+				continue
+			}
+			h := opts.Heuristic(child)
+			n := nodes.add(arenaNode{state: p, parent: top, move: int8(i), g: uint8(g), h: uint16(h)})
+			index.add(&nodes, n)
+			q.push(opts.key(g, h), n)
+		}
+	}
+	stats.Frontier = q.Len()
+	return Node{}, false
+}
+
+// path returns the Node chain from the start of the search to the node at index i. The g of a node can be
+// above its depth once an ancestor was overwritten with a shorter path, so the chain counts depths itself
+func (a *arena) path(i int32, opts SearchOptions) Node {
+	moves := opts.Moves.moves()
+	var chain []int32
+	for ; i >= 0; i = a.at(i).parent {
+		chain = append(chain, i)
+	}
+	var n *Node
+	for j := len(chain) - 1; j >= 0; j-- {
+		an := a.at(chain[j])
+		s := an.state.unpack()
+		next := &Node{prev: n, s: &s, g: len(chain) - 1 - j, h: int(an.h)}
+		if an.move >= 0 {
+			next.move = moves[an.move]
+		}
+		next.f = opts.priority(*next)
+		n = next
+	}
+	return *n
+}
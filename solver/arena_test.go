@@ -0,0 +1,124 @@
+package solver
+
+import (
+	"math/rand"
+	"megaminx/puzzle"
+	"runtime"
+	"testing"
+)
+
+func TestPackState(t *testing.T) {
+	seen := make(map[packedState]puzzle.State)
+	for seed := int64(1); seed <= 200; seed++ {
+		s := scrambled(int(seed%puzzle.ScrambleMoves), seed)
+		p, ok := pack(&s)
+		if !ok {
+			t.Fatalf("seed %d: a scrambled state does not pack", seed)
+		}
+		if back := p.unpack(); back != s {
+			t.Fatalf("seed %d: unpack() returns another state", seed)
+		}
+		if other, ok := seen[p]; ok && other != s {
+			t.Fatalf("seed %d: two states pack the same", seed)
+		}
+		seen[p] = s
+	}
+
+	// states no turns reach still pack, as long as their stickers make pieces
+	pieces := puzzle.NewPieces()
+	pieces.CornerOri[0], pieces.EdgePerm[0], pieces.EdgePerm[1] = 2, 1, 0
+	s := pieces.State()
+	if p, ok := pack(&s); !ok || p.unpack() != s {
+		t.Error("a state with a twisted corner and two swapped edges does not pack and unpack")
+	}
+}
+
+func TestPackBrokenPieces(t *testing.T) {
+	fs := puzzle.EdgeFacelets()[3]
+	for name, paint := range map[string]func(s *puzzle.State){
+		"no such edge": func(s *puzzle.State) { s[fs[1].Face][fs[1].Tile] = s[fs[0].Face][fs[0].Tile] },
+		"no color":     func(s *puzzle.State) { s[fs[0].Face][fs[0].Tile] = 15 },
+		"edge twice": func(s *puzzle.State) {
+			o := puzzle.EdgeFacelets()[4]
+			s[o[0].Face][o[0].Tile], s[o[1].Face][o[1].Tile] = s[fs[0].Face][fs[0].Tile], s[fs[1].Face][fs[1].Tile]
+		},
+	} {
+		s := puzzle.NewState()
+		paint(&s)
+		if _, ok := pack(&s); ok {
+			t.Errorf("%s: pack() succeeded", name)
+		}
+		if _, _, ok := Search(s, SearchOptions{Heuristic: H}); ok {
+			t.Errorf("%s: Search() found a solution", name)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		perm := rng.Perm(puzzle.NumEdges)
+		first, second, ok := rank(perm, edgeSplit)
+		if !ok {
+			t.Fatalf("rank(%v) failed", perm)
+		}
+		got := make([]int, len(perm))
+		unrank(got, edgeSplit, first, second)
+		for j := range perm {
+			if got[j] != perm[j] {
+				t.Fatalf("unrank(rank(%v)) = %v", perm, got)
+			}
+		}
+	}
+	if _, _, ok := rank([]int{0, 1, 1}, 3); ok {
+		t.Error("rank() of a list with a value twice succeeded")
+	}
+}
+
+func TestSearchOverwritesNodes(t *testing.T) {
+	// greedy best-first reaches states again by shorter paths all the time, and the path it returns must
+	// still be made of the moves its nodes record
+	for seed := int64(1); seed <= 5; seed++ {
+		s := scrambled(6, seed)
+		node, stats, ok := Search(s, SearchOptions{Strategy: GreedyBestFirst, Heuristic: H, MaxNodes: 20000})
+		if !ok {
+			continue
+		}
+		path := Path(node)
+		checkSolves(t, s, path)
+		if stats.Depth != len(path) {
+			t.Errorf("seed %d: Depth = %d for a path of %d moves", seed, stats.Depth, len(path))
+		}
+	}
+}
+
+// BenchmarkSearchMemory reports the bytes allocated per generated node by A* in the arena and by the
+// search it replaced, which links Nodes by pointer and orders them with the priority queue. Weighted A*
+// with a weight of 1 is that search
+func BenchmarkSearchMemory(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		opts SearchOptions
+	}{
+		{"arena", SearchOptions{Strategy: AStar, Heuristic: H}},
+		{"nodes", SearchOptions{Strategy: WeightedAStar, Weight: 1, Heuristic: H}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			var bytes uint64
+			generated := 0
+			for i := 0; i < b.N; i++ {
+				s := scrambled(10, int64(i%4+1))
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				_, stats, ok := Search(s, bm.opts)
+				runtime.ReadMemStats(&after)
+				if !ok {
+					b.Fatal("no solution found")
+				}
+				bytes += after.TotalAlloc - before.TotalAlloc
+				generated += stats.Generated
+			}
+			b.ReportMetric(float64(bytes)/float64(generated), "B/node")
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// This file contains the benchmark. Every solver solves the same seeded
+// scrambles of each depth, and each trial is recorded with its time, nodes,
+// memory and solution length, then summarized per depth and solver.
+
+package solver
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"megaminx/puzzle"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// BenchSolver is a named solver compared by RunBenchmark
+type BenchSolver struct {
+	Name  string
+	Solve func(s puzzle.State) (puzzle.Sequence, Stats, error)
+}
+
+// SearchSolver returns a BenchSolver searching with opts
+func SearchSolver(name string, opts SearchOptions) BenchSolver {
+	return BenchSolver{name, func(s puzzle.State) (puzzle.Sequence, Stats, error) {
+		node, stats, ok := Search(s, opts)
+		if !ok {
+			return nil, stats, fmt.Errorf("no solution found after expanding %d nodes", stats.Expanded)
+		}
+		return Path(node), stats, nil
+	}}
+}
+
+// BenchConfig selects the scrambles of a benchmark
+type BenchConfig struct {
+	MinDepth int   // fewest random clockwise turns in a scramble
+	MaxDepth int   // most random clockwise turns in a scramble
+	Trials   int   // scrambles per depth
+	Seed     int64 // seeds the seeds of the scrambles, the same seed gives the same scrambles
+}
+
+// BenchTrial is the record of one solver solving one scramble
+type BenchTrial struct {
+	Depth       int     `json:"depth"`
+	Trial       int     `json:"trial"`
+	Seed        int64   `json:"seed"` // rebuilds the scramble alone with State.Scramble
+	Solver      string  `json:"solver"`
+	Scramble    string  `json:"scramble"`
+	Solved      bool    `json:"solved"`
+	Length      int     `json:"length"` // moves in the solution, -1 if none was found
+	Expanded    int     `json:"expanded"`
+	Generated   int     `json:"generated"`
+	MaxFrontier int     `json:"max_frontier"`
+	ElapsedMS   float64 `json:"elapsed_ms"`
+	AllocBytes  uint64  `json:"alloc_bytes"` // bytes allocated while solving
+	Error       string  `json:"error,omitempty"`
+}
+
+// Summary holds the mean, median and 95th percentile of a measurement
+type Summary struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P95    float64 `json:"p95"`
+}
+
+// BenchSummary summarizes the trials of one solver at one depth. Length only covers the solved trials
+type BenchSummary struct {
+	Depth      int     `json:"depth"`
+	Solver     string  `json:"solver"`
+	Trials     int     `json:"trials"`
+	Solved     int     `json:"solved"`
+	ElapsedMS  Summary `json:"elapsed_ms"`
+	Expanded   Summary `json:"expanded"`
+	Generated  Summary `json:"generated"`
+	AllocBytes Summary `json:"alloc_bytes"`
+	Length     Summary `json:"length"`
+}
+
+// RunBenchmark solves cfg.Trials scrambles of every depth from cfg.MinDepth to cfg.MaxDepth with each of
+// solvers, calling record with every trial as it finishes if it is not nil, and returns the summaries of
+// each depth and solver. Each solver first solves the solved state, so tables built on first use are not
+// counted against its first trial
+func RunBenchmark(cfg BenchConfig, solvers []BenchSolver, record func(BenchTrial)) []BenchSummary {
+	for _, sv := range solvers {
+		sv.Solve(puzzle.NewState())
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	var res []BenchSummary
+	for depth := cfg.MinDepth; depth <= cfg.MaxDepth; depth++ {
+		seeds := make([]int64, cfg.Trials)
+		scrambles := make([]puzzle.State, cfg.Trials)
+		sequences := make([]puzzle.Sequence, cfg.Trials)
+		for i := range scrambles {
+			seeds[i] = rng.Int63()
+			scrambles[i] = puzzle.NewState()
+			sequences[i] = scrambles[i].Scramble(depth, rand.New(rand.NewSource(seeds[i])))
+		}
+
+		for _, sv := range solvers {
+			var trials []BenchTrial
+			for i, s := range scrambles {
+				t := runTrial(sv, s)
+				t.Depth, t.Trial, t.Seed, t.Scramble = depth, i+1, seeds[i], sequences[i].String()
+				if record != nil {
+					record(t)
+				}
+				trials = append(trials, t)
+			}
+			res = append(res, summarizeTrials(depth, sv.Name, trials))
+		}
+	}
+	return res
+}
+
+// runTrial solves s with sv, measuring the time and memory it takes
+func runTrial(sv BenchSolver, s puzzle.State) BenchTrial {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	moves, stats, err := sv.Solve(s)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	t := BenchTrial{
+		Solver:      sv.Name,
+		Length:      -1,
+		Expanded:    stats.Expanded,
+		Generated:   stats.Generated,
+		MaxFrontier: stats.MaxFrontier,
+		ElapsedMS:   float64(elapsed) / float64(time.Millisecond),
+		AllocBytes:  after.TotalAlloc - before.TotalAlloc,
+	}
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	moves.Apply(&s)
+	t.Solved = s == puzzle.NewState()
+	if !t.Solved {
+		t.Error = "solution does not solve the scramble"
+		return t
+	}
+	t.Length = len(moves)
+	return t
+}
+
+// summarizeTrials summarizes the trials of solver at depth
+func summarizeTrials(depth int, solver string, trials []BenchTrial) BenchSummary {
+	var elapsed, expanded, generated, alloc, length []float64
+	solved := 0
+	for _, t := range trials {
+		elapsed = append(elapsed, t.ElapsedMS)
+		expanded = append(expanded, float64(t.Expanded))
+		generated = append(generated, float64(t.Generated))
+		alloc = append(alloc, float64(t.AllocBytes))
+		if t.Solved {
+			length = append(length, float64(t.Length))
+			solved++
+		}
+	}
+	return BenchSummary{
+		Depth:      depth,
+		Solver:     solver,
+		Trials:     len(trials),
+		Solved:     solved,
+		ElapsedMS:  summarize(elapsed),
+		Expanded:   summarize(expanded),
+		Generated:  summarize(generated),
+		AllocBytes: summarize(alloc),
+		Length:     summarize(length),
+	}
+}
+
+// summarize returns the summary of values, all zero if there are none
+func summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Summary{
+		Mean:   sum / float64(len(sorted)),
+		Median: percentile(sorted, 50),
+		P95:    percentile(sorted, 95),
+	}
+}
+
+// percentile returns the p-th percentile of sorted by the nearest rank method
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,188 @@
+// This file contains a cache of solved states. States are stored under the
+// canonical rotation of the state, so a position is found again however the
+// puzzle was held when it came up, and the stored solution is rotated back to
+// the orientation of the state looked up. A distance only holds for the move
+// set it was counted in, so the move set is part of the key.
+
+package solver
+
+import (
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"megaminx/puzzle"
+	"os"
+	"sync"
+)
+
+// cacheVersion is written at the start of cache files and bumped when cacheEntry changes
+const cacheVersion = 3
+
+// cacheKey is what a state is stored under: its canonical rotation and the move set of its distance
+type cacheKey struct {
+	State packedState
+	Moves MoveSet
+}
+
+// cacheEntry is a solved state, its distance and a solution, all in the canonical rotation
+type cacheEntry struct {
+	Key      cacheKey
+	Distance int
+	Solution puzzle.Sequence
+}
+
+// cacheFile is the content of a file written by Save
+type cacheFile struct {
+	Version int
+	Entries []cacheEntry
+}
+
+// SolutionCache is a least recently used cache of solutions, safe for concurrent use
+type SolutionCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // most recently used first, holding *cacheEntry
+	entries  map[cacheKey]*list.Element
+}
+
+// NewSolutionCache returns an empty cache holding up to capacity states
+func NewSolutionCache(capacity int) *SolutionCache {
+	return &SolutionCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// DefaultCache is checked by Solve and SearchCached before searching
+var DefaultCache = NewSolutionCache(4096)
+
+// Len returns the number of states in c
+func (c *SolutionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Get returns the distance of s in moves of ms and a shortest solution for it, if s or a rotation of it
+// was stored for ms in c
+func (c *SolutionCache) Get(s puzzle.State, ms MoveSet) (int, puzzle.Sequence, bool) {
+	state, sym, ok := canonical(&s)
+	if !ok {
+		return 0, nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[cacheKey{state, ms}]
+	if !ok {
+		return 0, nil, false
+	}
+	c.order.MoveToFront(el)
+	e := el.Value.(*cacheEntry)
+	return e.Distance, sym.Unmoves(e.Solution), true
+}
+
+// Put stores solution, a shortest solution of s in moves of ms, which takes distance moves. The least
+// recently used state is evicted if c is full. States whose stickers do not make pieces are not stored
+func (c *SolutionCache) Put(s puzzle.State, ms MoveSet, distance int, solution puzzle.Sequence) {
+	state, sym, ok := canonical(&s)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(&cacheEntry{Key: cacheKey{state, ms}, Distance: distance, Solution: sym.Moves(solution)})
+}
+
+// put stores e as the most recently used entry
+func (c *SolutionCache) put(e *cacheEntry) {
+	if el, ok := c.entries[e.Key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[e.Key] = c.order.PushFront(e)
+	for c.order.Len() > c.capacity {
+		last := c.order.Back()
+		delete(c.entries, last.Value.(*cacheEntry).Key)
+		c.order.Remove(last)
+	}
+}
+
+// Save writes the entries of c to path, least recently used first
+func (c *SolutionCache) Save(path string) error {
+	c.mu.Lock()
+	var entries []cacheEntry
+	for el := c.order.Back(); el != nil; el = el.Prev() {
+		entries = append(entries, *el.Value.(*cacheEntry))
+	}
+	c.mu.Unlock()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(cacheFile{cacheVersion, entries}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load adds the entries saved in path to c, keeping their order of use
+func (c *SolutionCache) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var file cacheFile
+	if err := gob.NewDecoder(f).Decode(&file); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if file.Version != cacheVersion {
+		return fmt.Errorf("%s: cache file version %d, expected %d", path, file.Version, cacheVersion)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range file.Entries {
+		c.put(&file.Entries[i])
+	}
+	return nil
+}
+
+// symmetries holds the rotations canonical tries
+var symmetries = puzzle.Symmetries()
+
+// canonical returns the smallest packed state among the rotations of s and the rotation that gives it, or
+// false if s can not be packed
+func canonical(s *puzzle.State) (packedState, *puzzle.Symmetry, bool) {
+	var best packedState
+	var bestSym *puzzle.Symmetry
+	for i := range symmetries {
+		sym := &symmetries[i]
+		t := sym.Apply(s)
+		p, ok := pack(&t)
+		if !ok {
+			return best, nil, false
+		}
+		if bestSym == nil || p.less(&best) {
+			best, bestSym = p, sym
+		}
+	}
+	return best, bestSym, true
+}
+
+// less orders packed states by their words, first word first
+func (p *packedState) less(q *packedState) bool {
+	for i := range p {
+		if p[i] != q[i] {
+			return p[i] < q[i]
+		}
+	}
+	return false
+}
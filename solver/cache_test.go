@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"megaminx/puzzle"
+	"path/filepath"
+	"testing"
+)
+
+// emptyCache makes DefaultCache an empty cache until the test ends
+func emptyCache(t *testing.T) {
+	old := DefaultCache
+	DefaultCache = NewSolutionCache(16)
+	t.Cleanup(func() { DefaultCache = old })
+}
+
+func TestSearchCached(t *testing.T) {
+	emptyCache(t)
+
+	s := scrambled(5, 2)
+	_, first, ok := SearchCached(s, SearchOptions{Heuristic: H})
+	if !ok || first.Expanded == 0 {
+		t.Fatalf("first search: ok %v after %d nodes", ok, first.Expanded)
+	}
+	node, second, ok := SearchCached(s, SearchOptions{Heuristic: H})
+	if !ok || second.Expanded != 0 {
+		t.Fatalf("second search: ok %v after %d nodes, want it answered from the cache", ok, second.Expanded)
+	}
+	checkSolves(t, s, Path(node))
+	if second.Depth != first.Depth {
+		t.Errorf("cached solution has %d moves, want %d", second.Depth, first.Depth)
+	}
+}
+
+func TestSearchCachedMoveSets(t *testing.T) {
+	emptyCache(t)
+	s := puzzle.NewState()
+	puzzle.Sequence{{Face: 0, Turns: -1}}.Apply(&s)
+
+	_, stats, ok := SearchCached(s, SearchOptions{Heuristic: H, Moves: FaceTurns})
+	if !ok || stats.Depth != 1 {
+		t.Fatalf("face turn search: ok %v, %d moves, want 1", ok, stats.Depth)
+	}
+	// a clockwise turn takes four counter-clockwise ones, which the cached answer must not replace
+	node, stats, ok := SearchCached(s, SearchOptions{Heuristic: H})
+	if !ok || stats.Depth != 4 || stats.Expanded == 0 {
+		t.Fatalf("counter-clockwise search: ok %v, %d moves after %d nodes, want 4 moves found by searching", ok, stats.Depth, stats.Expanded)
+	}
+	checkSolves(t, s, Path(node))
+}
+
+func TestSearchCachedOnlyOptimal(t *testing.T) {
+	custom := func(s puzzle.State) int { return 2 * H(s) }
+	for _, opts := range []SearchOptions{
+		{Strategy: WeightedAStar, Heuristic: H},
+		{Strategy: GreedyBestFirst, Heuristic: H},
+		{Strategy: BeamSearch, Heuristic: H},
+		{Strategy: AStar, Heuristic: custom},
+	} {
+		emptyCache(t)
+		SearchCached(scrambled(4, 1), opts)
+		if n := DefaultCache.Len(); n != 0 {
+			t.Errorf("%s search with %s: %d states cached, want none", opts.Strategy, heuristicName(opts), n)
+		}
+	}
+	for _, opts := range []SearchOptions{
+		{Strategy: AStar, Heuristic: H},
+		{Strategy: UniformCost, Heuristic: custom},
+		{Strategy: IDAStar, Heuristic: H, Moves: FifthTurns},
+	} {
+		emptyCache(t)
+		SearchCached(scrambled(3, 1), opts)
+		if n := DefaultCache.Len(); n != 1 {
+			t.Errorf("%s search with %s: %d states cached, want 1", opts.Strategy, heuristicName(opts), n)
+		}
+	}
+}
+
+func TestSolutionCacheRotations(t *testing.T) {
+	c := NewSolutionCache(16)
+	s := scrambled(4, 5)
+	node, stats, ok := Search(s, SearchOptions{Heuristic: H})
+	if !ok {
+		t.Fatal("no solution found")
+	}
+	c.Put(s, CounterClockwise, stats.Depth, Path(node))
+	for _, sym := range puzzle.Symmetries() {
+		r := sym.Apply(&s)
+		d, moves, ok := c.Get(r, CounterClockwise)
+		if !ok || d != stats.Depth {
+			t.Fatalf("Get() of a rotation = %d, %v, want %d moves", d, ok, stats.Depth)
+		}
+		checkSolves(t, r, moves)
+	}
+	if _, _, ok := c.Get(s, FaceTurns); ok {
+		t.Error("Get() found a solution stored for another move set")
+	}
+}
+
+func TestSolutionCacheSaveLoad(t *testing.T) {
+	c := NewSolutionCache(16)
+	for seed := int64(1); seed <= 3; seed++ {
+		s := scrambled(3, seed)
+		node, _, _ := Search(s, SearchOptions{Heuristic: H})
+		c.Put(s, FifthTurns, len(Path(node)), Path(node))
+	}
+	path := filepath.Join(t.TempDir(), "cache")
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewSolutionCache(16)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != c.Len() {
+		t.Errorf("loaded %d states, want %d", loaded.Len(), c.Len())
+	}
+	s := scrambled(3, 2)
+	if _, moves, ok := loaded.Get(s, FifthTurns); !ok {
+		t.Error("a saved state is missing after Load")
+	} else {
+		checkSolves(t, s, moves)
+	}
+}
@@ -0,0 +1,257 @@
+// This file contains checkpointing for long searches. While searching, A*,
+// uniform-cost, greedy best-first and IDA* periodically write everything
+// needed to carry on to a file: the arena and frontier of the best-first
+// searches, or the bound and current path of IDA*. ResumeSearch reads such a
+// file and continues the search where the checkpoint left it.
+
+package solver
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"megaminx/puzzle"
+	"os"
+	"reflect"
+	"time"
+)
+
+const checkpointVersion = 3
+
+// checkpoint is the file format of a checkpoint, written with encoding/gob
+type checkpoint struct {
+	Version int
+	Start   puzzle.State
+
+	// options the search must be resumed with
+	Strategy  Strategy
+	TieBreak  TieBreak
+	MaxNodes  int
+	Heuristic string
+	MoveSet   MoveSet
+
+	Expanded, Generated, MaxFrontier int
+	Elapsed                          time.Duration
+
+	// A*, uniform-cost and greedy best-first: the arena, with the packed state of node i in
+	// States[4*i:4*i+4], and the buckets of the frontier
+	States  []uint64
+	Parents []int32
+	Moves   []int8
+	G       []uint8
+	H       []uint16
+	Buckets [][]int32
+	Min     int
+
+	// IDA*: the bound of the current iteration, the smallest f found above it so far, and the turns made
+	// to reach the node about to be expanded
+	Bound, NextBound int
+	Path             []int8
+}
+
+// heuristicName names the heuristic opts uses, so a search is not resumed with a different one. H given
+// explicitly is recognized, other heuristics given are "custom"
+func heuristicName(opts SearchOptions) string {
+	switch {
+	case opts.Heuristic != nil && reflect.ValueOf(opts.Heuristic).Pointer() == reflect.ValueOf(H).Pointer():
+		return "H"
+	case opts.Heuristic != nil:
+		return "custom"
+	case tables != nil:
+		return "tables"
+	default:
+		return "H"
+	}
+}
+
+// setArena stores nodes and the frontier q in c, with node pending put back into bucket key
+func (c *checkpoint) setArena(nodes *arena, q *bucketQueue, pending int32, key int) {
+	c.States = make([]uint64, 0, nodes.n*len(packedState{}))
+	c.Parents = make([]int32, nodes.n)
+	c.Moves = make([]int8, nodes.n)
+	c.G = make([]uint8, nodes.n)
+	c.H = make([]uint16, nodes.n)
+	for i := range c.Parents {
+		n := nodes.at(int32(i))
+		c.States = append(c.States, n.state[:]...)
+		c.Parents[i], c.Moves[i], c.G[i], c.H[i] = n.parent, n.move, n.g, n.h
+	}
+
+	c.Buckets = make([][]int32, len(q.buckets))
+	for key := range c.Buckets {
+		c.Buckets[key] = q.bucket(key)
+	}
+	for len(c.Buckets) <= key {
+		c.Buckets = append(c.Buckets, nil)
+	}
+	if q.fifo { // pending is the next to be popped, at the front of a queue or the top of a stack
+		c.Buckets[key] = append([]int32{pending}, c.Buckets[key]...)
+	} else {
+		c.Buckets[key] = append(append([]int32{}, c.Buckets[key]...), pending)
+	}
+	c.Min = q.min
+	if key < c.Min {
+		c.Min = key
+	}
+}
+
+// checkArena returns an error if the arena and frontier in c do not fit together
+func (c *checkpoint) checkArena() error {
+	n := len(c.Parents)
+	if len(c.States) != n*len(packedState{}) || len(c.Moves) != n || len(c.G) != n || len(c.H) != n {
+		return errors.New("checkpoint arena is damaged")
+	}
+	moves := len(c.MoveSet.moves())
+	for i, parent := range c.Parents {
+		if parent < -1 || int(parent) >= n || c.Moves[i] < -1 || int(c.Moves[i]) >= moves {
+			return errors.New("checkpoint arena is damaged")
+		}
+	}
+	for _, b := range c.Buckets {
+		for _, node := range b {
+			if node < 0 || int(node) >= n {
+				return errors.New("checkpoint frontier is damaged")
+			}
+		}
+	}
+	return nil
+}
+
+// arena returns the arena stored in c
+func (c *checkpoint) arena() arena {
+	var nodes arena
+	for i := range c.Parents {
+		n := arenaNode{parent: c.Parents[i], move: c.Moves[i], g: c.G[i], h: c.H[i]}
+		copy(n.state[:], c.States[i*len(packedState{}):])
+		nodes.add(n)
+	}
+	return nodes
+}
+
+// checkpointer decides when a search writes a checkpoint or stops, and writes the checkpoints
+type checkpointer struct {
+	opts    SearchOptions
+	header  checkpoint // the fields that stay the same for the whole search
+	stats   *Stats
+	before  time.Duration // time spent before the search was resumed
+	started time.Time
+	last    time.Time // when the last checkpoint was written
+	polls   int
+}
+
+func newCheckpointer(s puzzle.State, opts SearchOptions, heuristic string, stats *Stats) *checkpointer {
+	now := time.Now()
+	return &checkpointer{
+		opts: opts,
+		header: checkpoint{
+			Version:   checkpointVersion,
+			Start:     s,
+			Strategy:  opts.Strategy,
+			TieBreak:  opts.TieBreak,
+			MaxNodes:  opts.MaxNodes,
+			Heuristic: heuristic,
+			MoveSet:   opts.Moves,
+		},
+		stats:   stats,
+		before:  stats.Elapsed,
+		started: now,
+		last:    now,
+	}
+}
+
+// elapsed returns the time spent searching, including before the search was resumed
+func (c *checkpointer) elapsed() time.Duration {
+	return c.before + time.Since(c.started)
+}
+
+// poll is called before every node is expanded. It reports whether a checkpoint is due and whether the
+// search should stop, which also calls for a checkpoint. Only every 1024th call looks at the clock and
+// reports progress
+func (c *checkpointer) poll() (save, stop bool) {
+	c.polls++
+	if c.polls%1024 != 0 {
+		return false, false
+	}
+	if c.opts.Progress != nil {
+		stats := *c.stats
+		stats.Elapsed = c.elapsed()
+		c.opts.Progress(stats)
+	}
+	select {
+	case <-c.opts.Stop:
+		stop = true
+	default:
+	}
+	if c.opts.Checkpoint == "" {
+		return false, stop
+	}
+	return stop || time.Since(c.last) >= c.opts.CheckpointEvery, stop
+}
+
+// save writes a checkpoint holding the search progress that fill adds to the header. Errors are kept in
+// the stats rather than stopping the search
+func (c *checkpointer) save(fill func(cp *checkpoint)) {
+	cp := c.header
+	cp.Expanded, cp.Generated, cp.MaxFrontier = c.stats.Expanded, c.stats.Generated, c.stats.MaxFrontier
+	cp.Elapsed = c.elapsed()
+	fill(&cp)
+	c.last = time.Now()
+	if err := writeCheckpoint(c.opts.Checkpoint, &cp); err != nil {
+		c.stats.Err = err
+	}
+}
+
+// writeCheckpoint writes cp to path, through a temporary file so a crash never leaves half a checkpoint
+func writeCheckpoint(path string, cp *checkpoint) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(cp); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ResumeSearch continues the search saved in the checkpoint file at path. opts must use the same strategy,
+// tie-breaking rule, node limit and heuristic as the search that wrote the checkpoint
+func ResumeSearch(path string, opts SearchOptions) (Node, Stats, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Node{}, Stats{}, false, err
+	}
+	defer f.Close()
+	var cp checkpoint
+	if err := gob.NewDecoder(f).Decode(&cp); err != nil {
+		return Node{}, Stats{}, false, fmt.Errorf("%s: %v", path, err)
+	}
+	if cp.Version != checkpointVersion {
+		return Node{}, Stats{}, false, fmt.Errorf("%s: checkpoint version %d, expected %d", path, cp.Version, checkpointVersion)
+	}
+
+	switch {
+	case cp.Strategy != opts.Strategy:
+		err = fmt.Errorf("checkpoint was written by %s search, not %s", cp.Strategy, opts.Strategy)
+	case cp.TieBreak != opts.TieBreak && cp.Strategy != IDAStar:
+		err = fmt.Errorf("checkpoint was written breaking ties by %s, not %s", cp.TieBreak, opts.TieBreak)
+	case cp.MaxNodes != opts.MaxNodes:
+		err = fmt.Errorf("checkpoint was written with a limit of %d nodes, not %d", cp.MaxNodes, opts.MaxNodes)
+	case cp.Heuristic != heuristicName(opts):
+		err = fmt.Errorf("checkpoint was written using heuristic %s, not %s", cp.Heuristic, heuristicName(opts))
+	case cp.MoveSet != opts.Moves:
+		err = fmt.Errorf("checkpoint was written with move set %s, not %s", cp.MoveSet, opts.Moves)
+	default:
+		err = cp.checkArena()
+	}
+	if err != nil {
+		return Node{}, Stats{}, false, fmt.Errorf("%s: %v", path, err)
+	}
+
+	node, stats, ok := search(cp.Start, opts, &cp)
+	return node, stats, ok, nil
+}
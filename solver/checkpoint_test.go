@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchStop(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+	_, stats, ok := Search(scrambled(12, 1), SearchOptions{Heuristic: H, Stop: stop})
+	if ok || !stats.Stopped {
+		t.Errorf("Search() = %v with Stopped %v after the stop channel was closed", ok, stats.Stopped)
+	}
+}
+
+func TestResumeSearch(t *testing.T) {
+	// deep enough for the search to be stopped before it solves the scramble
+	depths := map[Strategy]int{AStar: 10, UniformCost: 4, IDAStar: 10}
+	for strategy, depth := range depths {
+		for _, tie := range []TieBreak{PreferDeeper, FIFO, LIFO} {
+			s := scrambled(depth, 3)
+			opts := SearchOptions{Strategy: strategy, TieBreak: tie, Heuristic: H}
+			_, want, ok := Search(s, opts)
+			if !ok {
+				t.Fatalf("%s, %s: no solution found", strategy, tie)
+			}
+
+			stop := make(chan struct{})
+			close(stop)
+			path := filepath.Join(t.TempDir(), "checkpoint")
+			stopped := opts
+			stopped.Checkpoint, stopped.CheckpointEvery, stopped.Stop = path, time.Hour, stop
+			if _, stats, ok := Search(s, stopped); ok || !stats.Stopped {
+				t.Fatalf("%s, %s: search was not stopped", strategy, tie)
+			}
+
+			node, got, ok, err := ResumeSearch(path, opts)
+			if err != nil {
+				t.Fatalf("%s, %s: %v", strategy, tie, err)
+			}
+			if !ok {
+				t.Fatalf("%s, %s: resumed search found no solution", strategy, tie)
+			}
+			checkSolves(t, s, Path(node))
+			if got.Depth != want.Depth || strategy != IDAStar && got.Expanded != want.Expanded {
+				t.Errorf("%s, %s: resumed search found depth %d after %d nodes, want %d after %d",
+					strategy, tie, got.Depth, got.Expanded, want.Depth, want.Expanded)
+			}
+		}
+	}
+}
+
+func TestResumeSearchMismatch(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	opts := SearchOptions{Heuristic: H, Checkpoint: path, CheckpointEvery: time.Hour, Stop: stop}
+	if _, _, ok := Search(scrambled(12, 1), opts); ok {
+		t.Fatal("search was not stopped")
+	}
+	for _, other := range []SearchOptions{
+		{Strategy: UniformCost, Heuristic: H},
+		{TieBreak: FIFO, Heuristic: H},
+		{MaxNodes: 10, Heuristic: H},
+		{Moves: FaceTurns, Heuristic: H},
+	} {
+		if _, _, _, err := ResumeSearch(path, other); err == nil {
+			t.Errorf("ResumeSearch() with %+v did not fail", other)
+		}
+	}
+	if _, _, _, err := ResumeSearch(filepath.Join(t.TempDir(), "missing"), SearchOptions{Heuristic: H}); err == nil {
+		t.Error("ResumeSearch() of a missing file did not fail")
+	}
+}
@@ -0,0 +1,132 @@
+// This file contains IDA*, which searches depth-first up to a bound on g + h
+// and raises the bound to the smallest g + h that exceeded it until a solution
+// is found. It only keeps the current path in memory, so it can run for as long
+// as it takes, saving the bound and path in checkpoints along the way.
+
+package solver
+
+import (
+	"math"
+	"megaminx/puzzle"
+)
+
+// idaStar searches s with IDA*, continuing from resume if it is not nil
+func idaStar(s puzzle.State, opts SearchOptions, stats *Stats, cp *checkpointer, resume *checkpoint) (Node, bool) {
+	bound, next := opts.Heuristic(s), math.MaxInt
+	moves := opts.Moves.moves()
+	var path []int8 // indices in moves of the turns made
+	var skip []int8 // path of the resumed node, children before it on each depth were searched already
+	if resume != nil {
+		bound, next, skip = resume.Bound, resume.NextBound, resume.Path
+	}
+
+	var stop bool
+	var dfs func(st puzzle.State, g int) bool
+	dfs = func(st puzzle.State, g int) bool {
+		h := opts.Heuristic(st)
+		if f := g + h; f > bound {
+			if f < next {
+				next = f
+			}
+			return false
+		}
+		if st == solvedState {
+			return true
+		}
+		if opts.MaxNodes > 0 && stats.Expanded >= opts.MaxNodes {
+			stop = true
+			return false
+		}
+		if save, stopped := cp.poll(); save || stopped {
+			if save {
+				cp.save(func(c *checkpoint) {
+					c.Bound, c.NextBound, c.Path = bound, next, append([]int8{}, path...)
+				})
+			}
+			if stopped {
+				stats.Stopped, stop = true, true
+				return false
+			}
+		}
+		stats.Expanded++
+
+		first := 0
+		if g < len(skip) {
+			first = int(skip[g])
+		}
+		for i := first; i < len(moves); i++ {
+			if redundant(path, i, moves, opts.Moves) {
+				continue
+			}
+			stats.Generated++
+			child := puzzle.CopyState(st)
+			moves[i].Apply(&child)
+			path = append(path, int8(i))
+			if dfs(child, g+1) {
+				return true
+			}
+			path = path[:len(path)-1]
+			skip = nil // the resumed branch, if any, is done
+			if stop {
+				return false
+			}
+		}
+		return false
+	}
+
+	for {
+		if dfs(s, 0) {
+			seq := make(puzzle.Sequence, len(path))
+			for i, mv := range path {
+				seq[i] = moves[mv]
+			}
+			return pathNode(s, seq, opts), true
+		}
+		if stop || next == math.MaxInt {
+			return Node{}, false
+		}
+		bound, next = next, math.MaxInt
+	}
+}
+
+// redundant reports whether making moves[i] after path can be skipped, because a shorter or equivalent
+// sequence is searched anyway. Of two turns of faces that do not share an edge, only the order with the lower
+// face first is searched, and turns of one face in a row are only searched while no shorter turn of the
+// move set does the same: up to four counter-clockwise fifths, up to two fifths the same way, or a single
+// face turn
+func redundant(path []int8, i int, moves []puzzle.Move, ms MoveSet) bool {
+	n := len(path)
+	if n == 0 {
+		return false
+	}
+	mv, prev := moves[i], moves[path[n-1]]
+	if prev.Face != mv.Face {
+		return !adjacent(prev.Face, mv.Face) && mv.Face < prev.Face
+	}
+	run := 1 // turns of mv.Face already at the end of path
+	for run < n && moves[path[n-1-run]].Face == mv.Face {
+		run++
+	}
+	switch ms {
+	case FaceTurns:
+		return true
+	case FifthTurns:
+		return prev.Turns != mv.Turns || run >= 2
+	default:
+		return run >= 4
+	}
+}
+
+// pathNode returns the Node chain reached from s by the moves in path
+func pathNode(s puzzle.State, path puzzle.Sequence, opts SearchOptions) Node {
+	n := &Node{s: &s, h: opts.Heuristic(s)}
+	n.f = opts.priority(*n)
+	for _, mv := range path {
+		st := puzzle.CopyState(*n.s)
+		mv.Apply(&st)
+		next := &Node{prev: n, s: &st, g: n.g + 1, h: opts.Heuristic(st), move: mv}
+		next.f = opts.priority(*next)
+		n = next
+	}
+	return *n
+}
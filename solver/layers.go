@@ -0,0 +1,574 @@
+// This file contains a layer-by-layer solver that follows the human method for
+// the megaminx: the star and the corners of the U face, the F2L slots below it,
+// the remaining side faces (S2L) and finally the last layer on D. Unlike Solve,
+// which can only handle a few random turns, it solves any valid state.
+//
+// Pieces are placed one at a time. Each piece is placed with a small search over
+// an algorithm table of single turns, commutators and their conjugates, using
+// only the algorithms that leave every piece already placed where it is.
+
+package solver
+
+import (
+	"fmt"
+	"math/bits"
+	"megaminx/puzzle"
+	"sync"
+)
+
+// Stage is one step of a staged solution
+type Stage struct {
+	Name  string
+	Moves puzzle.Sequence
+}
+
+// pieceStep records that an algorithm moves the piece in position from to position to, adding twist
+type pieceStep struct {
+	from, to, twist byte
+}
+
+// algorithm is an entry in the algorithm table: a move sequence and its effect on the pieces
+type algorithm struct {
+	moves   puzzle.Sequence
+	support uint64 // bit i is set if corner position i is affected, bit puzzle.NumCorners+i if edge position i is
+	corners []pieceStep
+	edges   []pieceStep
+}
+
+// algorithmStep is a step of algorithm alg, the index of an entry in the algorithm table
+type algorithmStep struct {
+	alg  int
+	step pieceStep
+}
+
+var (
+	algorithms     []algorithm
+	algorithmsOnce sync.Once
+
+	// cornerSteps and edgeSteps list the algorithms that move the piece in each position
+	cornerSteps [puzzle.NumCorners][]algorithmStep
+	edgeSteps   [puzzle.NumEdges][]algorithmStep
+)
+
+// target is a position to be solved, edge reports whether it is an edge or a corner position
+type target struct {
+	edge bool
+	pos  int
+}
+
+// bit returns the bit of t in a support mask
+func (t target) bit() uint64 {
+	if t.edge {
+		return 1 << uint(puzzle.NumCorners+t.pos)
+	}
+	return 1 << uint(t.pos)
+}
+
+// support returns the support mask of a
+func support(a *puzzle.PieceMove) uint64 {
+	var mask uint64
+	for i := range a.CP {
+		if a.CP[i] != byte(i) || a.CO[i] != 0 {
+			mask |= 1 << uint(i)
+		}
+	}
+	for i := range a.EP {
+		if a.EP[i] != byte(i) || a.EO[i] != 0 {
+			mask |= 1 << uint(puzzle.NumCorners+i)
+		}
+	}
+	return mask
+}
+
+// steps lists the pieces a moves
+func steps(a *puzzle.PieceMove) ([]pieceStep, []pieceStep) {
+	var corners, edges []pieceStep
+	for pos := range a.CP {
+		if a.CP[pos] != byte(pos) || a.CO[pos] != 0 {
+			corners = append(corners, pieceStep{a.CP[pos], byte(pos), a.CO[pos]})
+		}
+	}
+	for pos := range a.EP {
+		if a.EP[pos] != byte(pos) || a.EO[pos] != 0 {
+			edges = append(edges, pieceStep{a.EP[pos], byte(pos), a.EO[pos]})
+		}
+	}
+	return corners, edges
+}
+
+// allMoves returns every turn of every face by one or two fifths in either direction
+func allMoves() []puzzle.Move {
+	var res []puzzle.Move
+	for face := 0; face < 12; face++ {
+		for _, turns := range []int{1, -1, 2, -2} {
+			res = append(res, puzzle.Move{Face: face, Turns: turns})
+		}
+	}
+	return res
+}
+
+// adjacent reports whether faces f1 and f2 share an edge, false if f1 is no face
+func adjacent(f1, f2 int) bool {
+	neighbors, err := puzzle.Neighbors(f1)
+	if err != nil {
+		return false
+	}
+	for _, f := range neighbors {
+		if f == f2 {
+			return true
+		}
+	}
+	return false
+}
+
+// cycle is the effect of an algorithm that only moves up to three pieces of one kind, stored sparsely.
+// Unused steps are left zero, which no real step is, since a step always moves or twists its piece
+type cycle struct {
+	edge  bool
+	steps [3]pieceStep
+}
+
+// newCycle returns the cycle of pm, which must only move corners or only move edges
+func newCycle(pm *puzzle.PieceMove) cycle {
+	var c cycle
+	n := 0
+	for pos := range pm.CP {
+		if pm.CP[pos] != byte(pos) || pm.CO[pos] != 0 {
+			c.steps[n] = pieceStep{pm.CP[pos], byte(pos), pm.CO[pos]}
+			n++
+		}
+	}
+	for pos := range pm.EP {
+		if pm.EP[pos] != byte(pos) || pm.EO[pos] != 0 {
+			c.edge = true
+			c.steps[n] = pieceStep{pm.EP[pos], byte(pos), pm.EO[pos]}
+			n++
+		}
+	}
+	return c
+}
+
+// len returns the number of pieces c moves
+func (c *cycle) len() int {
+	n := 0
+	for n < len(c.steps) && c.steps[n] != (pieceStep{}) {
+		n++
+	}
+	return n
+}
+
+// conjugate returns the effect of S C S', where pm is the effect of S. A piece S moves into position from
+// is moved on by C and then back out by S', so only the positions of the steps change
+func (c cycle) conjugate(pm *puzzle.PieceMove) cycle {
+	twists, perm, ori := byte(3), pm.CP[:], pm.CO[:]
+	if c.edge {
+		twists, perm, ori = 2, pm.EP[:], pm.EO[:]
+	}
+	res := cycle{edge: c.edge}
+	for i := 0; i < c.len(); i++ {
+		st := c.steps[i]
+		res.steps[i] = pieceStep{perm[st.from], perm[st.to], (st.twist + twists + ori[st.from] - ori[st.to]) % twists}
+	}
+	res.sort()
+	return res
+}
+
+// then returns the effect of c followed by d, if the result still moves at most three pieces
+func (c cycle) then(d cycle) (cycle, bool) {
+	twists := byte(3)
+	if c.edge {
+		twists = 2
+	}
+	// follow every piece c or d moves through both of them
+	var from [6]byte
+	n := 0
+	for _, x := range []*cycle{&c, &d} {
+		for i := 0; i < x.len(); i++ {
+			dup := false
+			for _, f := range from[:n] {
+				dup = dup || f == x.steps[i].from
+			}
+			if !dup {
+				from[n] = x.steps[i].from
+				n++
+			}
+		}
+	}
+
+	res := cycle{edge: c.edge}
+	k := 0
+	for _, f := range from[:n] {
+		pos, twist := f, byte(0)
+		for _, x := range []*cycle{&c, &d} {
+			for i := 0; i < x.len(); i++ {
+				if x.steps[i].from == pos {
+					pos, twist = x.steps[i].to, (twist+x.steps[i].twist)%twists
+					break
+				}
+			}
+		}
+		if pos == f && twist == 0 {
+			continue
+		}
+		if k == len(res.steps) {
+			return cycle{}, false
+		}
+		res.steps[k] = pieceStep{f, pos, twist}
+		k++
+	}
+	res.sort()
+	return res, true
+}
+
+// untwisted returns c without its twists, so cycles that permute the same pieces the same way are equal
+func (c cycle) untwisted() cycle {
+	for i := range c.steps {
+		c.steps[i].twist = 0
+	}
+	return c
+}
+
+// sort orders the steps of c by destination, so equal effects compare equal
+func (c *cycle) sort() {
+	n := c.len()
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && c.steps[j].to < c.steps[j-1].to; j-- {
+			c.steps[j], c.steps[j-1] = c.steps[j-1], c.steps[j]
+		}
+	}
+}
+
+// algorithm returns the algorithm table entry for moves with effect c
+func (c cycle) algorithm(moves puzzle.Sequence) algorithm {
+	alg := algorithm{moves: moves}
+	for i := 0; i < c.len(); i++ {
+		st := c.steps[i]
+		if c.edge {
+			alg.edges = append(alg.edges, st)
+			alg.support |= 1 << uint(puzzle.NumCorners+int(st.to))
+		} else {
+			alg.corners = append(alg.corners, st)
+			alg.support |= 1 << uint(st.to)
+		}
+	}
+	return alg
+}
+
+// buildAlgorithms fills the algorithm table. It holds every single turn and every insert X Y X', every
+// commutator of two of those that only cycles three pieces, the conjugates of those by a single turn, and
+// products of two such cycles that only twist or flip pieces in place
+func buildAlgorithms() {
+	var table []algorithm
+
+	// single turns and inserts X Y X', which take pieces out of the way with X, turn Y and put them back
+	moves := allMoves()
+	effect := make([]puzzle.PieceMove, len(moves))
+	for i, mv := range moves {
+		effect[i], _ = puzzle.MoveEffect(mv) // allMoves only turns faces that exist
+	}
+	var parts []puzzle.PieceMove
+	var partMoves []puzzle.Sequence
+	for i, mv := range moves {
+		parts = append(parts, effect[i])
+		partMoves = append(partMoves, puzzle.Sequence{mv})
+	}
+	for x := range moves {
+		for y := range moves {
+			if adjacent(moves[x].Face, moves[y].Face) {
+				a := puzzle.Compose(&effect[x], &effect[y])
+				a = puzzle.Compose(&a, &effect[x^1]) // allMoves lists each turn next to its inverse
+				parts = append(parts, a)
+				partMoves = append(partMoves, puzzle.Sequence{moves[x], moves[y], moves[x].Inverse()})
+			}
+		}
+	}
+	masks := make([]uint64, len(parts))
+	inverses := make([]puzzle.PieceMove, len(parts))
+	for i := range parts {
+		masks[i] = support(&parts[i])
+		inverses[i] = parts[i].Inverse()
+		table = append(table, algorithm{moves: partMoves[i], support: masks[i]})
+	}
+	for i := range table {
+		table[i].corners, table[i].edges = steps(&parts[i])
+	}
+
+	// the shortest sequence found for each cycle. Sequences are only built once they are known to be
+	// shorter, most candidates repeat a cycle already found
+	cycles := make(map[cycle]puzzle.Sequence)
+	var order []cycle
+	addCycle := func(c cycle, n int, build func() puzzle.Sequence) {
+		old, ok := cycles[c]
+		if ok && len(old) <= n {
+			return
+		}
+		if !ok {
+			order = append(order, c)
+		}
+		cycles[c] = build()
+	}
+
+	// if A and B only have one position in common, the commutator A B A' B' is a 3-cycle
+	for i := range parts {
+		for j := range parts {
+			if bits.OnesCount64(masks[i]&masks[j]) != 1 {
+				continue
+			}
+			c := puzzle.Compose(&parts[i], &parts[j])
+			c = puzzle.Compose(&c, &inverses[i])
+			c = puzzle.Compose(&c, &inverses[j])
+			addCycle(newCycle(&c), 2*(len(partMoves[i])+len(partMoves[j])), func() puzzle.Sequence {
+				seq := append(append(puzzle.Sequence{}, partMoves[i]...), partMoves[j]...)
+				return append(append(seq, partMoves[i].Inverse()...), partMoves[j].Inverse()...)
+			})
+		}
+	}
+
+	// conjugates S C S' move the pieces of a 3-cycle to other positions
+	for _, c := range order[:len(order):len(order)] {
+		for s, mv := range moves {
+			if mv.Turns == 1 || mv.Turns == -1 {
+				inner := cycles[c]
+				addCycle(c.conjugate(&effect[s]), len(inner)+2, func() puzzle.Sequence {
+					return append(append(puzzle.Sequence{mv}, inner...), mv.Inverse())
+				})
+			}
+		}
+	}
+
+	// a cycle followed by one with the inverse permutation leaves every piece in place, only twisting or
+	// flipping them
+	byPerm := make(map[cycle][]cycle)
+	for _, c := range order {
+		byPerm[c.untwisted()] = append(byPerm[c.untwisted()], c)
+	}
+	for _, c := range order {
+		inv := cycle{edge: c.edge}
+		for i := 0; i < c.len(); i++ {
+			inv.steps[i] = pieceStep{from: c.steps[i].to, to: c.steps[i].from}
+		}
+		inv.sort()
+		for _, d := range byPerm[inv.untwisted()] {
+			if prod, ok := c.then(d); ok && prod.len() > 0 {
+				first, second := cycles[c], cycles[d]
+				addCycle(prod, len(first)+len(second), func() puzzle.Sequence {
+					return append(append(puzzle.Sequence{}, first...), second...)
+				})
+			}
+		}
+	}
+
+	for _, c := range order {
+		table = append(table, c.algorithm(cycles[c]))
+	}
+	for i, alg := range table {
+		for _, st := range alg.corners {
+			cornerSteps[st.from] = append(cornerSteps[st.from], algorithmStep{i, st})
+		}
+		for _, st := range alg.edges {
+			edgeSteps[st.from] = append(edgeSteps[st.from], algorithmStep{i, st})
+		}
+	}
+	algorithms = table
+}
+
+// layerStages lists the positions solved in each stage of the layer-by-layer method, in order
+func layerStages() ([]string, [][]target) {
+	const top, bottom = 0, 6
+	var star, corners, f2l, s2l, last []target
+	placed := make(map[target]bool)
+	add := func(list *[]target, t target) {
+		if !placed[t] {
+			placed[t] = true
+			*list = append(*list, t)
+		}
+	}
+
+	up, _ := puzzle.Neighbors(top)
+	down, _ := puzzle.Neighbors(bottom)
+	for k := 0; k < 5; k++ {
+		add(&star, edgeBetween(top, up[k]))
+	}
+	for k := 0; k < 5; k++ {
+		add(&corners, cornerBetween(top, up[k], up[(k+1)%5]))
+	}
+	for k := 0; k < 5; k++ {
+		a, b := up[k], up[(k+1)%5]
+		add(&f2l, edgeBetween(a, b))
+		for _, c := range facePieces(a) {
+			if !c.edge && onFace(c, b) && !onFace(c, top) {
+				add(&f2l, c)
+			}
+		}
+	}
+	// the remaining side faces, one at a time, leaving out the pieces on the bottom face
+	for _, face := range append(up[:], down[:]...) {
+		for _, t := range facePieces(face) {
+			if t.edge && !onFace(t, bottom) {
+				add(&s2l, t)
+			}
+		}
+		for _, t := range facePieces(face) {
+			if !t.edge && !onFace(t, bottom) {
+				add(&s2l, t)
+			}
+		}
+	}
+	for _, t := range facePieces(bottom) {
+		if t.edge {
+			add(&last, t)
+		}
+	}
+	for _, t := range facePieces(bottom) {
+		if !t.edge {
+			add(&last, t)
+		}
+	}
+	return []string{"star", "first layer", "F2L", "S2L", "last layer"}, [][]target{star, corners, f2l, s2l, last}
+}
+
+// facePieces returns the positions of the five edges and five corners around face
+func facePieces(face int) []target {
+	var res []target
+	for tile := 0; tile < 10; tile++ {
+		if tile%2 == 1 {
+			res = append(res, target{true, puzzle.EdgeAt(puzzle.Facelet{Face: face, Tile: tile})})
+		} else {
+			res = append(res, target{false, puzzle.CornerAt(puzzle.Facelet{Face: face, Tile: tile})})
+		}
+	}
+	return res
+}
+
+// onFace reports whether position t has a sticker on face
+func onFace(t target, face int) bool {
+	if t.edge {
+		return edgeFacelets[t.pos][0].Face == face || edgeFacelets[t.pos][1].Face == face
+	}
+	for _, fl := range cornerFacelets[t.pos] {
+		if fl.Face == face {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeBetween and cornerBetween return the position between the given faces, which layerStages only calls
+// with faces that meet
+func edgeBetween(a, b int) target {
+	pos, _ := puzzle.EdgeBetween(a, b)
+	return target{true, pos}
+}
+
+func cornerBetween(a, b, c int) target {
+	pos, _ := puzzle.CornerBetween(a, b, c)
+	return target{false, pos}
+}
+
+// SolveLayers solves s with the layer-by-layer method and returns the moves of each stage
+func SolveLayers(s puzzle.State) ([]Stage, error) {
+	p, err := s.Pieces()
+	if err != nil {
+		return nil, err
+	}
+	algorithmsOnce.Do(buildAlgorithms)
+
+	names, stages := layerStages()
+	var res []Stage
+	var solved uint64
+	for i, targets := range stages {
+		stage := Stage{Name: names[i]}
+		for _, t := range targets {
+			moves, ok := placePiece(&p, t, solved)
+			if !ok {
+				return nil, fmt.Errorf("%s: no algorithm places %s", names[i], t)
+			}
+			moves.ApplyPieces(&p)
+			stage.Moves = append(stage.Moves, moves...)
+			solved |= t.bit()
+		}
+		res = append(res, stage)
+	}
+	return res, nil
+}
+
+// String names the faces position t sits between
+func (t target) String() string {
+	if t.edge {
+		return "edge " + puzzle.EdgeName(t.pos)
+	}
+	return "corner " + puzzle.CornerName(t.pos)
+}
+
+// placePiece returns the cheapest moves that bring the piece belonging in t home using only algorithms
+// that keep every position in solved. It is Dijkstra's algorithm over the positions and twists of that piece
+func placePiece(p *puzzle.Pieces, t target, solved uint64) (puzzle.Sequence, bool) {
+	twists := 3
+	perm, ori := p.CornerPerm[:], p.CornerOri[:]
+	if t.edge {
+		twists = 2
+		perm, ori = p.EdgePerm[:], p.EdgeOri[:]
+	}
+
+	// a state of the piece is pos*twists + ori
+	var start int
+	for pos, piece := range perm {
+		if int(piece) == t.pos {
+			start = pos*twists + int(ori[pos])
+		}
+	}
+	goal := t.pos * twists
+
+	n := len(perm) * twists
+	dist := make([]int, n)
+	prev := make([]int, n)
+	via := make([]int, n)
+	done := make([]bool, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[start] = 0
+
+	for {
+		// take the closest state not yet done
+		cur := -1
+		for i := range dist {
+			if !done[i] && dist[i] >= 0 && (cur < 0 || dist[i] < dist[cur]) {
+				cur = i
+			}
+		}
+		if cur < 0 {
+			return nil, false
+		}
+		if cur == goal {
+			break
+		}
+		done[cur] = true
+
+		pos, o := cur/twists, cur%twists
+		var steps []algorithmStep
+		if t.edge {
+			steps = edgeSteps[pos]
+		} else {
+			steps = cornerSteps[pos]
+		}
+		for _, as := range steps {
+			alg := &algorithms[as.alg]
+			if alg.support&solved != 0 {
+				continue
+			}
+			next := int(as.step.to)*twists + (o+int(as.step.twist))%twists
+			if d := dist[cur] + len(alg.moves); dist[next] < 0 || d < dist[next] {
+				dist[next] = d
+				prev[next] = cur
+				via[next] = as.alg
+			}
+		}
+	}
+
+	var res puzzle.Sequence
+	for cur := goal; cur != start; cur = prev[cur] {
+		res = append(append(puzzle.Sequence{}, algorithms[via[cur]].moves...), res...)
+	}
+	return res, true
+}
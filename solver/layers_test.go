@@ -0,0 +1,35 @@
+package solver
+
+import (
+	"megaminx/puzzle"
+	"reflect"
+	"testing"
+)
+
+func TestSolveLayers(t *testing.T) {
+	names, _ := layerStages()
+	for seed := int64(1); seed <= 3; seed++ {
+		s := scrambled(puzzle.ScrambleMoves, seed)
+		stages, err := SolveLayers(s)
+		if err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+			continue
+		}
+		checkSolves(t, s, JoinStages(stages))
+		var got []string
+		for _, st := range stages {
+			got = append(got, st.Name)
+		}
+		if !reflect.DeepEqual(got, names) {
+			t.Errorf("seed %d: stages %v, want %v", seed, got, names)
+		}
+	}
+}
+
+func TestSolveLayersInvalid(t *testing.T) {
+	p := puzzle.NewPieces()
+	p.EdgeOri[0] = 1
+	if _, err := SolveLayers(p.State()); err == nil {
+		t.Error("SolveLayers() of a state with a flipped edge succeeded")
+	}
+}
@@ -0,0 +1,635 @@
+// This file contains a multi-phase solver that works down a chain of nested
+// subgroups, each generated by fewer moves than the one before.
+//
+// The face phases take faces out of play one at a time: U, the five faces
+// around it and then the side faces around D, until only D and two faces next
+// to it are left. A face phase turns only the faces still in play and solves
+// the pieces that lie on taken-out faces alone, so no later phase moves them
+// again. It is an IDA* search over the states of those few pieces, pruned with
+// tables that hold the exact number of turns needed to solve groups of up to
+// three of them.
+//
+// Three faces make for long phases, so the pieces left, on the last side face
+// and on D, are solved two at a time by algorithm phases. Their generators are
+// the algorithms of the layer method's algorithm table that keep the pieces
+// solved so far in place, a smaller set in each phase, and each has a pruning
+// table holding the exact number of moves those algorithms need to solve its
+// pair from every position, so solving it is a walk down the table.
+
+package solver
+
+import (
+	"fmt"
+	"megaminx/puzzle"
+	"strings"
+	"sync"
+)
+
+// pieceStates is the number of states of a single piece: 20 corner positions with 3 twists, or 30 edge
+// positions with 2 flips
+const pieceStates = 60
+
+// faceTables is the most pieces a pruning table of a face phase covers, which keeps it at
+// pieceStates^3 entries
+const faceTables = 3
+
+// minFaces is the fewest faces a face phase turns. Turning two faces alone can not in general solve the
+// pieces on the second of them
+const minFaces = 3
+
+// maxFaceTurns bounds the search of a face phase, which only runs out for states the phase can not solve
+const maxFaceTurns = 30
+
+// facePhase solves the pieces in targets by turning only faces, which keep every piece solved by earlier
+// phases in place
+type facePhase struct {
+	stage   string
+	turns   []puzzle.Move // the turns of the faces in play
+	targets []target
+	tables  []pruningTable
+}
+
+// pruningTable holds the turns a face phase needs to solve some of its pieces from every state they can
+// be in, indexed by the states of those pieces in base pieceStates. Unreachable states hold unreached
+type pruningTable struct {
+	pieces []int // indices into the targets of the phase
+	dist   []byte
+}
+
+// unreached marks states of a pruning table that can not be solved
+const unreached = ^byte(0)
+
+// algorithmPhase solves the pieces belonging in targets, using only algorithms that keep the positions in
+// solved. A phase of a single piece lists it twice
+type algorithmPhase struct {
+	stage   string
+	targets [2]target
+	solved  uint64
+	dist    []uint16 // pruning table indexed by coordinate, unreachable coordinates hold noDist
+}
+
+// noDist marks coordinates of a pruning table that can not be solved
+const noDist = ^uint16(0)
+
+var (
+	facePhases      []facePhase
+	algorithmPhases []algorithmPhase
+	phasesOnce      sync.Once
+
+	// turnStates holds the state of a corner (index 0) or edge (index 1) after each turn of allMoves
+	turnStates [][2][pieceStates]byte
+)
+
+// twists returns the number of orientations of the piece in t
+func (t target) twists() int {
+	if t.edge {
+		return 2
+	}
+	return 3
+}
+
+// kind returns the index of the pieces like t in turnStates
+func (t target) kind() int {
+	if t.edge {
+		return 1
+	}
+	return 0
+}
+
+// pieceState returns the state of the piece belonging in t, which is its position*twists + orientation, or
+// an error if p is not a valid placement of the pieces
+func pieceState(p *puzzle.Pieces, t target) (int, error) {
+	perm, ori := p.CornerPerm[:], p.CornerOri[:]
+	if t.edge {
+		perm, ori = p.EdgePerm[:], p.EdgeOri[:]
+	}
+	for pos, piece := range perm {
+		if int(piece) == t.pos {
+			if int(ori[pos]) >= t.twists() {
+				return 0, fmt.Errorf("%s has orientation %d", t, ori[pos])
+			}
+			return pos*t.twists() + int(ori[pos]), nil
+		}
+	}
+	return 0, fmt.Errorf("the piece of %s is missing", t)
+}
+
+// coord returns the coordinate of the pair of phase ph in p
+func (ph *algorithmPhase) coord(p *puzzle.Pieces) (int, error) {
+	a, err := pieceState(p, ph.targets[0])
+	if err != nil {
+		return 0, err
+	}
+	b, err := pieceState(p, ph.targets[1])
+	return a*pieceStates + b, err
+}
+
+// usable reports whether alg lies in the subgroup ph works in
+func (ph *algorithmPhase) usable(alg *algorithm) bool {
+	return alg.support&ph.solved == 0
+}
+
+// index returns the entry of tb for the states of the pieces of a phase
+func (tb *pruningTable) index(states []int) int {
+	i := 0
+	for k := len(tb.pieces) - 1; k >= 0; k-- {
+		i = i*pieceStates + states[tb.pieces[k]]
+	}
+	return i
+}
+
+// buildTable fills a pruning table for the given pieces of ph with a breadth-first search from the
+// solved pieces. The turns of a phase include the inverse of every turn, so distances from the solved
+// pieces are also distances to them
+func (ph *facePhase) buildTable(pieces []int) pruningTable {
+	tb := pruningTable{pieces: pieces}
+	size := 1
+	for range pieces {
+		size *= pieceStates
+	}
+	tb.dist = make([]byte, size)
+	for i := range tb.dist {
+		tb.dist[i] = unreached
+	}
+
+	states := make([]int, len(ph.targets))
+	for i, t := range ph.targets {
+		states[i] = t.pos * t.twists()
+	}
+	kinds := make([]int, len(pieces))
+	for k, i := range pieces {
+		kinds[k] = ph.targets[i].kind()
+	}
+	goal := tb.index(states)
+	tb.dist[goal] = 0
+	queue := []int32{int32(goal)}
+	for len(queue) > 0 {
+		cur := int(queue[0])
+		queue = queue[1:]
+		for k, c := 0, cur; k < len(pieces); k, c = k+1, c/pieceStates {
+			states[pieces[k]] = c % pieceStates
+		}
+		for _, mv := range ph.turns {
+			ts := &turnStates[turnIndex(mv)]
+			next := 0
+			for k := len(pieces) - 1; k >= 0; k-- {
+				next = next*pieceStates + int(ts[kinds[k]][states[pieces[k]]])
+			}
+			if tb.dist[next] == unreached {
+				tb.dist[next] = tb.dist[cur] + 1
+				queue = append(queue, int32(next))
+			}
+		}
+	}
+	return tb
+}
+
+// turnIndex returns the index of mv in allMoves
+func turnIndex(mv puzzle.Move) int {
+	i := 4 * mv.Face
+	switch mv.Turns {
+	case -1:
+		i++
+	case 2:
+		i += 2
+	case -2:
+		i += 3
+	}
+	return i
+}
+
+// bound returns the largest distance the pruning tables of ph give for states, a lower bound on the turns
+// needed to solve them, or unreached if some table finds them unsolvable
+func (ph *facePhase) bound(states []int) byte {
+	var h byte
+	for i := range ph.tables {
+		if d := ph.tables[i].dist[ph.tables[i].index(states)]; d > h {
+			h = d
+		}
+	}
+	return h
+}
+
+// faceSearch is an IDA* search of a face phase, with the states of its pieces after each turn of path
+type faceSearch struct {
+	ph     *facePhase
+	states [][]int
+	path   []puzzle.Move
+}
+
+// search extends the path at depth, with states[depth] the states reached, to a solution of at most limit
+// turns. Turning the face turned last is pointless, and faces that do not meet commute, so those are
+// only turned in increasing order
+func (fs *faceSearch) search(depth, limit int) bool {
+	cur := fs.states[depth]
+	h := int(fs.ph.bound(cur))
+	if h == 0 {
+		return true
+	}
+	if depth+h > limit {
+		return false
+	}
+	next := fs.states[depth+1]
+	for _, mv := range fs.ph.turns {
+		if depth > 0 {
+			last := fs.path[depth-1].Face
+			if mv.Face == last || !adjacent(mv.Face, last) && mv.Face < last {
+				continue
+			}
+		}
+		ts := &turnStates[turnIndex(mv)]
+		for i, t := range fs.ph.targets {
+			next[i] = int(ts[t.kind()][cur[i]])
+		}
+		fs.path = append(fs.path[:depth], mv)
+		if fs.search(depth+1, limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// solve finds the fewest turns of ph that solve its pieces in p and applies them to p
+func (ph *facePhase) solve(p *puzzle.Pieces) (puzzle.Sequence, error) {
+	fs := faceSearch{ph: ph, states: make([][]int, maxFaceTurns+1)}
+	for i := range fs.states {
+		fs.states[i] = make([]int, len(ph.targets))
+	}
+	for i, t := range ph.targets {
+		st, err := pieceState(p, t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ph.stage, err)
+		}
+		fs.states[0][i] = st
+	}
+	h := ph.bound(fs.states[0])
+	if h == unreached {
+		return nil, fmt.Errorf("%s: can not solve %s", ph.stage, targetList(ph.targets))
+	}
+	for limit := int(h); limit <= maxFaceTurns; limit++ {
+		if fs.search(0, limit) {
+			moves := append(puzzle.Sequence{}, fs.path...)
+			if err := moves.ApplyPieces(p); err != nil {
+				return nil, err
+			}
+			return moves, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: can not solve %s in %d turns", ph.stage, targetList(ph.targets), maxFaceTurns)
+}
+
+// targetList returns the names of targets separated by commas
+func targetList(targets []target) string {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// tableGroups returns the groups of pieces the pruning tables of a phase cover: all of them if there are
+// few enough, otherwise the edges and the corners with each edge, which needs far fewer tables than every
+// group of faceTables and prunes almost as well. Every piece is in some group, so the pieces are solved
+// when every table says so
+func tableGroups(targets []target) [][]int {
+	var corners, edges []int
+	for i, t := range targets {
+		if t.edge {
+			edges = append(edges, i)
+		} else {
+			corners = append(corners, i)
+		}
+	}
+	if len(targets) <= faceTables {
+		return [][]int{append(corners, edges...)}
+	}
+	chunks := func(pieces []int) [][]int {
+		var res [][]int
+		for k := 0; k < len(pieces); k += faceTables {
+			end := k + faceTables
+			if end > len(pieces) {
+				end = len(pieces)
+			}
+			res = append(res, pieces[k:end])
+		}
+		return res
+	}
+	res := chunks(edges)
+	if len(corners) >= faceTables || len(edges) == 0 {
+		return append(res, chunks(corners)...)
+	}
+	for _, e := range edges {
+		res = append(res, append(append([]int{}, corners...), e))
+	}
+	return res
+}
+
+// stepsFrom lists, for the piece of t in position pos, the algorithm steps that move it
+func stepsFrom(t target, pos int) []algorithmStep {
+	if t.edge {
+		return edgeSteps[pos]
+	}
+	return cornerSteps[pos]
+}
+
+// stepsInto lists the algorithm steps that move a piece of the same kind as t into position pos
+func stepsInto(t target, pos int) []algorithmStep {
+	if t.edge {
+		return edgeStepsInto[pos]
+	}
+	return cornerStepsInto[pos]
+}
+
+// move returns the state of a piece in state after alg
+func move(alg *algorithm, t target, state int) int {
+	twists := t.twists()
+	pos, ori := state/twists, state%twists
+	steps := alg.corners
+	if t.edge {
+		steps = alg.edges
+	}
+	for _, st := range steps {
+		if int(st.from) == pos {
+			return int(st.to)*twists + (ori+int(st.twist))%twists
+		}
+	}
+	return state
+}
+
+// unmove returns the state a piece was in before alg brought it to state
+func unmove(alg *algorithm, t target, state int) int {
+	twists := t.twists()
+	pos, ori := state/twists, state%twists
+	steps := alg.corners
+	if t.edge {
+		steps = alg.edges
+	}
+	for _, st := range steps {
+		if int(st.to) == pos {
+			return int(st.from)*twists + (ori+twists-int(st.twist))%twists
+		}
+	}
+	return state
+}
+
+// buildTable fills the pruning table of ph with Dijkstra's algorithm, running backwards from the solved pair.
+// Every move costs at most a few dozen turns, so the frontier is kept in buckets by distance
+func (ph *algorithmPhase) buildTable() {
+	a, b := ph.targets[0], ph.targets[1]
+	ph.dist = make([]uint16, pieceStates*pieceStates)
+	for i := range ph.dist {
+		ph.dist[i] = noDist
+	}
+	goal := a.pos*a.twists()*pieceStates + b.pos*b.twists()
+	ph.dist[goal] = 0
+
+	// the steps into each position of algorithms in the subgroup, most algorithms leave it in later phases
+	usableInto := func(t target) [][]algorithmStep {
+		n := puzzle.NumCorners
+		if t.edge {
+			n = puzzle.NumEdges
+		}
+		res := make([][]algorithmStep, n)
+		for pos := range res {
+			for _, as := range stepsInto(t, pos) {
+				if ph.usable(&algorithms[as.alg]) {
+					res[pos] = append(res[pos], as)
+				}
+			}
+		}
+		return res
+	}
+	intoA, intoB := usableInto(a), usableInto(b)
+
+	buckets := [][]int{{goal}}
+	for d := 0; d < len(buckets); d++ {
+		for _, cur := range buckets[d] {
+			if int(ph.dist[cur]) != d {
+				continue // reached again later with a shorter distance
+			}
+			sa, sb := cur/pieceStates, cur%pieceStates
+			relax := func(as algorithmStep) {
+				alg := &algorithms[as.alg]
+				next := unmove(alg, a, sa)*pieceStates + unmove(alg, b, sb)
+				nd := d + len(alg.moves)
+				if ph.dist[next] != noDist && int(ph.dist[next]) <= nd {
+					return
+				}
+				ph.dist[next] = uint16(nd)
+				for len(buckets) <= nd {
+					buckets = append(buckets, nil)
+				}
+				buckets[nd] = append(buckets[nd], next)
+			}
+			posA := target{a.edge, sa / a.twists()}.bit()
+			for _, as := range intoA[sa/a.twists()] {
+				relax(as)
+			}
+			for _, as := range intoB[sb/b.twists()] {
+				if algorithms[as.alg].support&posA == 0 { // algorithms moving both pieces were relaxed above
+					relax(as)
+				}
+			}
+		}
+	}
+}
+
+var (
+	cornerStepsInto [puzzle.NumCorners][]algorithmStep
+	edgeStepsInto   [puzzle.NumEdges][]algorithmStep
+)
+
+// faceChain returns the order the face phases take faces out of play in: U, the faces around it, and then
+// the side faces around D, each below two faces already taken out
+func faceChain() []int {
+	const top, bottom = 0, 6
+	up, _ := puzzle.Neighbors(top)
+	down, _ := puzzle.Neighbors(bottom)
+	chain := append([]int{top}, up[:]...)
+	for k := 0; k < 5; k++ {
+		for _, f := range down {
+			if adjacent(f, up[k]) && adjacent(f, up[(k+1)%5]) {
+				chain = append(chain, f)
+			}
+		}
+	}
+	return chain
+}
+
+// phaseName returns the name of a phase turning faces, the generators of its subgroup
+func phaseName(faces []int) string {
+	names := make([]string, len(faces))
+	for i, f := range faces {
+		names[i] = puzzle.FaceName(f)
+	}
+	return "<" + strings.Join(names, " ") + ">"
+}
+
+// buildPhases takes the faces out of play along faceChain, with a face phase for each face that leaves
+// pieces on taken-out faces alone, down to minFaces faces, and splits the pieces left into pairs in the
+// order of the layer method
+func buildPhases() {
+	algorithmsOnce.Do(buildAlgorithms)
+	turns := allMoves()
+	turnStates = make([][2][pieceStates]byte, len(turns))
+	for i, mv := range turns {
+		pm, _ := puzzle.MoveEffect(mv) // allMoves only turns faces that exist
+		var alg algorithm
+		alg.corners, alg.edges = steps(&pm)
+		for st := 0; st < pieceStates; st++ {
+			turnStates[i][0][st] = byte(move(&alg, target{false, 0}, st))
+			turnStates[i][1][st] = byte(move(&alg, target{true, 0}, st))
+		}
+	}
+
+	var all []target
+	for pos := 0; pos < puzzle.NumCorners; pos++ {
+		all = append(all, target{false, pos})
+	}
+	for pos := 0; pos < puzzle.NumEdges; pos++ {
+		all = append(all, target{true, pos})
+	}
+
+	// the pieces no face in play moves, which are all solved once the face phases are done
+	var solved uint64
+	var out [12]bool
+	for k, face := range faceChain() {
+		if 12-k < minFaces {
+			break
+		}
+		var faces []int
+		for f := 0; f < 12; f++ {
+			if !out[f] {
+				faces = append(faces, f)
+			}
+		}
+		var phTurns []puzzle.Move
+		for _, mv := range turns {
+			if !out[mv.Face] {
+				phTurns = append(phTurns, mv)
+			}
+		}
+		out[face] = true
+
+		ph := facePhase{stage: phaseName(faces), turns: phTurns}
+		for _, t := range all {
+			moved := false
+			for f := 0; f < 12; f++ {
+				moved = moved || !out[f] && onFace(t, f)
+			}
+			if !moved && solved&t.bit() == 0 {
+				ph.targets = append(ph.targets, t)
+				solved |= t.bit()
+			}
+		}
+		if len(ph.targets) == 0 {
+			continue
+		}
+		for _, pieces := range tableGroups(ph.targets) {
+			ph.tables = append(ph.tables, ph.buildTable(pieces))
+		}
+		facePhases = append(facePhases, ph)
+	}
+
+	for i, alg := range algorithms {
+		for _, st := range alg.corners {
+			cornerStepsInto[st.to] = append(cornerStepsInto[st.to], algorithmStep{i, st})
+		}
+		for _, st := range alg.edges {
+			edgeStepsInto[st.to] = append(edgeStepsInto[st.to], algorithmStep{i, st})
+		}
+	}
+	names, stages := layerStages()
+	for i, targets := range stages {
+		var left []target
+		for _, t := range targets {
+			if solved&t.bit() == 0 {
+				left = append(left, t)
+			}
+		}
+		for k := 0; k < len(left); k += 2 {
+			ph := algorithmPhase{stage: names[i], targets: [2]target{left[k], left[k]}, solved: solved}
+			if k+1 < len(left) {
+				ph.targets[1] = left[k+1]
+			}
+			ph.buildTable()
+			algorithmPhases = append(algorithmPhases, ph)
+			solved |= ph.targets[0].bit() | ph.targets[1].bit()
+		}
+	}
+}
+
+// SolvePhases solves s one phase at a time and returns the moves of each phase. Face phases are named by
+// the faces they turn, algorithm phases by the stage of the layer method their pieces belong to
+func SolvePhases(s puzzle.State) ([]Stage, error) {
+	p, err := s.Pieces()
+	if err != nil {
+		return nil, err
+	}
+	phasesOnce.Do(buildPhases)
+
+	var res []Stage
+	for i := range facePhases {
+		moves, err := facePhases[i].solve(&p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, Stage{Name: facePhases[i].stage, Moves: moves})
+	}
+	for i := range algorithmPhases {
+		ph := &algorithmPhases[i]
+		moves, err := ph.solve(&p)
+		if err != nil {
+			return nil, err
+		}
+		if len(res) > 0 && res[len(res)-1].Name == ph.stage {
+			res[len(res)-1].Moves = append(res[len(res)-1].Moves, moves...)
+		} else {
+			res = append(res, Stage{Name: ph.stage, Moves: moves})
+		}
+	}
+	return res, nil
+}
+
+// solve walks down the pruning table of ph from the pair's coordinate in p to the solved pair, applying
+// the moves to p
+func (ph *algorithmPhase) solve(p *puzzle.Pieces) (puzzle.Sequence, error) {
+	a, b := ph.targets[0], ph.targets[1]
+	var res puzzle.Sequence
+	for {
+		cur, err := ph.coord(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", ph.stage, err)
+		}
+		d := ph.dist[cur]
+		if d == noDist {
+			return nil, fmt.Errorf("%s: can not solve %s and %s", ph.stage, a, b)
+		}
+		if d == 0 {
+			return res, nil
+		}
+
+		sa, sb := cur/pieceStates, cur%pieceStates
+		var next *algorithm
+		for _, steps := range [][]algorithmStep{stepsFrom(a, sa/a.twists()), stepsFrom(b, sb/b.twists())} {
+			for _, as := range steps {
+				alg := &algorithms[as.alg]
+				if !ph.usable(alg) {
+					continue
+				}
+				if nd := ph.dist[move(alg, a, sa)*pieceStates+move(alg, b, sb)]; nd != noDist && int(nd)+len(alg.moves) == int(d) {
+					next = alg
+					break
+				}
+			}
+			if next != nil {
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%s: pruning table for %s and %s is inconsistent", ph.stage, a, b)
+		}
+		next.moves.ApplyPieces(p)
+		res = append(res, next.moves...)
+	}
+}
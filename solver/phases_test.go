@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"megaminx/puzzle"
+	"testing"
+)
+
+func TestSolvePhases(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		s := scrambled(puzzle.ScrambleMoves, seed)
+		stages, err := SolvePhases(s)
+		if err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+			continue
+		}
+		checkSolves(t, s, JoinStages(stages))
+
+		// each face phase only turns the faces of its subgroup
+		for i, ph := range facePhases {
+			turns := make(map[int]bool)
+			for _, mv := range ph.turns {
+				turns[mv.Face] = true
+			}
+			for _, mv := range stages[i].Moves {
+				if !turns[mv.Face] {
+					t.Errorf("seed %d: phase %s turns %s", seed, ph.stage, mv)
+				}
+			}
+		}
+
+		layers, err := SolveLayers(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, m := len(JoinStages(stages)), len(JoinStages(layers)); n >= m {
+			t.Errorf("seed %d: %d moves in phases, %d in layers", seed, n, m)
+		}
+	}
+}
+
+func TestFacePhasesKeepSolvedPieces(t *testing.T) {
+	phasesOnce.Do(buildPhases)
+	for i := range facePhases {
+		if i > 0 && len(facePhases[i].turns) >= len(facePhases[i-1].turns) {
+			t.Errorf("phase %s turns no fewer faces than %s", facePhases[i].stage, facePhases[i-1].stage)
+		}
+		for _, later := range facePhases[i+1:] {
+			for _, mv := range later.turns {
+				for _, tg := range facePhases[i].targets {
+					if onFace(tg, mv.Face) {
+						t.Errorf("phase %s moves %s, solved by %s", later.stage, tg, facePhases[i].stage)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSolvePhasesSolved(t *testing.T) {
+	stages, err := SolvePhases(puzzle.NewState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moves := JoinStages(stages); len(moves) != 0 {
+		t.Errorf("SolvePhases() of the solved state = %s", moves)
+	}
+}
+
+func TestSolvePhasesInvalid(t *testing.T) {
+	p := puzzle.NewPieces()
+	p.EdgeOri[0] = 1
+	if _, err := SolvePhases(p.State()); err == nil {
+		t.Error("SolvePhases() of a state with a flipped edge succeeded")
+	}
+}
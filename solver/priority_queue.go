@@ -0,0 +1,167 @@
+// This file contains a min-heap implementation of a priority queue. Elements
+// are ordered by a priority, with ties between equal priorities broken by a
+// configurable rule, and the priority of an element still in the queue can be
+// lowered through the Item handle returned when it was pushed.
+
+package solver
+
+import (
+	"fmt"
+)
+
+// TieBreak selects which of two elements with equal priority is popped first
+type TieBreak int
+
+const (
+	PreferDeeper TieBreak = iota // the element with the larger depth, e.g. g in A*, then FIFO
+	FIFO                         // the element pushed first
+	LIFO                         // the element pushed last
+)
+
+var tieBreakNames = map[TieBreak]string{
+	PreferDeeper: "deeper",
+	FIFO:         "FIFO",
+	LIFO:         "LIFO",
+}
+
+// String returns the name of tie-breaking rule t
+func (t TieBreak) String() string {
+	if name, ok := tieBreakNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TieBreak(%d)", int(t))
+}
+
+// Item is an element of a PriorityQueue. It doubles as the handle used to decrease its priority
+type Item[T any] struct {
+	Value    T
+	priority float64
+	depth    int
+	seq      uint64 // order the item was pushed in, used by FIFO and LIFO
+	index    int    // position in the heap, -1 once popped
+}
+
+// PriorityQueue is a min-heap of items ordered by priority. The zero value is an empty queue preferring deeper items
+type PriorityQueue[T any] struct {
+	items []*Item[T]
+	tie   TieBreak
+	seq   uint64
+}
+
+// NewPriorityQueue returns an empty queue that breaks ties with tie
+func NewPriorityQueue[T any](tie TieBreak) *PriorityQueue[T] {
+	return &PriorityQueue[T]{tie: tie}
+}
+
+// Len returns the number of items in the queue
+func (pq *PriorityQueue[T]) Len() int {
+	return len(pq.items)
+}
+
+// less reports whether the item at index i should be popped before the item at index j
+func (pq *PriorityQueue[T]) less(i, j int) bool {
+	a, b := pq.items[i], pq.items[j]
+	if a.priority != b.priority {
+		return a.priority < b.priority
+	}
+	switch pq.tie {
+	case LIFO:
+		return a.seq > b.seq
+	case PreferDeeper:
+		if a.depth != b.depth {
+			return a.depth > b.depth
+		}
+	}
+	return a.seq < b.seq
+}
+
+// swap swaps the items at index i and j, keeping their indices up to date
+func (pq *PriorityQueue[T]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+// parent, left and right return the indices of the parent and children of i in the 0-based heap array
+func parent(i int) int {
+	return (i - 1) / 2
+}
+
+func left(i int) int {
+	return 2*i + 1
+}
+
+func right(i int) int {
+	return 2*i + 2
+}
+
+// up moves the item at index i up the heap until its parent comes before it
+func (pq *PriorityQueue[T]) up(i int) {
+	for i > 0 && pq.less(i, parent(i)) {
+		pq.swap(i, parent(i))
+		i = parent(i)
+	}
+}
+
+// down enforces the min-heap property for the heap rooted at index i
+func (pq *PriorityQueue[T]) down(i int) {
+	for {
+		smallest := i
+		if l := left(i); l < pq.Len() && pq.less(l, smallest) {
+			smallest = l
+		}
+		if r := right(i); r < pq.Len() && pq.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		pq.swap(i, smallest)
+		i = smallest
+	}
+}
+
+// Push inserts value with the given priority and depth, and returns its handle
+func (pq *PriorityQueue[T]) Push(value T, priority float64, depth int) *Item[T] {
+	it := &Item[T]{Value: value, priority: priority, depth: depth, seq: pq.seq, index: pq.Len()}
+	pq.seq++
+	pq.items = append(pq.items, it)
+	pq.up(it.index)
+	return it
+}
+
+// Pop removes and returns the value that comes first
+func (pq *PriorityQueue[T]) Pop() T {
+	top := pq.items[0]
+	last := pq.Len() - 1
+	pq.swap(0, last)
+	pq.items[last] = nil
+	pq.items = pq.items[:last]
+	if last > 0 {
+		pq.down(0)
+	}
+	top.index = -1
+	return top.Value
+}
+
+// Peek returns the value that comes first without removing it
+func (pq *PriorityQueue[T]) Peek() T {
+	return pq.items[0].Value
+}
+
+// Contains reports whether it is still in the queue
+func (pq *PriorityQueue[T]) Contains(it *Item[T]) bool {
+	return it.index >= 0 && it.index < pq.Len() && pq.items[it.index] == it
+}
+
+// DecreaseKey replaces the value of it and lowers its priority, setting its depth. It reports false, leaving
+// the queue unchanged, if it has been popped or priority is higher than its current one
+func (pq *PriorityQueue[T]) DecreaseKey(it *Item[T], value T, priority float64, depth int) bool {
+	if !pq.Contains(it) || priority > it.priority {
+		return false
+	}
+	it.Value, it.priority, it.depth = value, priority, depth
+	pq.up(it.index)
+	pq.down(it.index) // with an equal priority, a smaller depth can move it down under PreferDeeper
+	return true
+}
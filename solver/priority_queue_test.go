@@ -0,0 +1,142 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+)
+
+// pushed is an element pushed in a priority queue test
+type pushed struct {
+	value    string
+	priority float64
+	depth    int
+}
+
+// popAll pops every value of pq, checking that Peek agrees with Pop
+func popAll(t *testing.T, pq *PriorityQueue[string]) []string {
+	t.Helper()
+	var res []string
+	for pq.Len() > 0 {
+		peeked := pq.Peek()
+		v := pq.Pop()
+		if v != peeked {
+			t.Fatalf("Pop() = %q, but Peek() returned %q", v, peeked)
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		tie    TieBreak
+		pushes []pushed
+		want   []string
+	}{
+		{"priority first", FIFO, []pushed{{"c", 3, 0}, {"a", 1, 0}, {"d", 4, 0}, {"b", 2, 0}}, []string{"a", "b", "c", "d"}},
+		{"FIFO", FIFO, []pushed{{"a", 1, 0}, {"b", 1, 5}, {"c", 1, 2}, {"z", 0, 0}}, []string{"z", "a", "b", "c"}},
+		{"LIFO", LIFO, []pushed{{"a", 1, 0}, {"b", 1, 5}, {"c", 1, 2}, {"z", 0, 0}}, []string{"z", "c", "b", "a"}},
+		{"deeper", PreferDeeper, []pushed{{"a", 1, 2}, {"b", 1, 5}, {"c", 1, 2}, {"d", 1, 9}}, []string{"d", "b", "a", "c"}},
+		{"deeper after priority", PreferDeeper, []pushed{{"a", 2, 9}, {"b", 1, 0}, {"c", 1, 1}}, []string{"c", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := NewPriorityQueue[string](tt.tie)
+			for _, p := range tt.pushes {
+				pq.Push(p.value, p.priority, p.depth)
+			}
+			if pq.Len() != len(tt.want) {
+				t.Fatalf("Len() = %d, want %d", pq.Len(), len(tt.want))
+			}
+			if got := popAll(t, pq); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("popped %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueDecreaseKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		tie      TieBreak
+		pushes   []pushed
+		decrease int    // index in pushes of the item decreased
+		to       pushed // its new value, priority and depth
+		ok       bool
+		want     []string
+	}{
+		{"to the front", FIFO, []pushed{{"a", 1, 0}, {"b", 2, 0}, {"c", 3, 0}}, 2, pushed{"c2", 0, 0}, true, []string{"c2", "a", "b"}},
+		{"to the middle", FIFO, []pushed{{"a", 1, 0}, {"b", 2, 0}, {"c", 3, 0}, {"d", 4, 0}}, 3, pushed{"d2", 2.5, 0}, true, []string{"a", "b", "d2", "c"}},
+		{"higher is refused", FIFO, []pushed{{"a", 1, 0}, {"b", 2, 0}}, 0, pushed{"a2", 5, 0}, false, []string{"a", "b"}},
+		{"equal keeps FIFO place", FIFO, []pushed{{"a", 1, 0}, {"b", 1, 0}}, 1, pushed{"b2", 1, 0}, true, []string{"a", "b2"}},
+		{"equal and deeper", PreferDeeper, []pushed{{"a", 1, 3}, {"b", 1, 1}}, 1, pushed{"b2", 1, 7}, true, []string{"b2", "a"}},
+		{"equal and shallower", PreferDeeper, []pushed{{"a", 1, 7}, {"b", 1, 3}}, 0, pushed{"a2", 1, 1}, true, []string{"b", "a2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := NewPriorityQueue[string](tt.tie)
+			var items []*Item[string]
+			for _, p := range tt.pushes {
+				items = append(items, pq.Push(p.value, p.priority, p.depth))
+			}
+			if ok := pq.DecreaseKey(items[tt.decrease], tt.to.value, tt.to.priority, tt.to.depth); ok != tt.ok {
+				t.Fatalf("DecreaseKey() = %v, want %v", ok, tt.ok)
+			}
+			if got := popAll(t, pq); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("popped %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityQueueZeroValue(t *testing.T) {
+	var pq PriorityQueue[string]
+	pq.Push("a", 1, 0)
+	pq.Push("b", 1, 1)
+	if got := popAll(t, &pq); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("popped %v, want [b a], the deeper first", got)
+	}
+}
+
+func TestPriorityQueuePopped(t *testing.T) {
+	pq := NewPriorityQueue[string](FIFO)
+	a := pq.Push("a", 1, 0)
+	b := pq.Push("b", 2, 0)
+	if !pq.Contains(a) || !pq.Contains(b) {
+		t.Fatal("Contains() = false for items still in the queue")
+	}
+	pq.Pop()
+	if pq.Contains(a) {
+		t.Error("Contains() = true for a popped item")
+	}
+	if pq.DecreaseKey(a, "a2", 0, 0) {
+		t.Error("DecreaseKey() = true for a popped item")
+	}
+	if got := popAll(t, pq); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("popped %v, want [b]", got)
+	}
+}
+
+func TestBestFirstShortest(t *testing.T) {
+	// weighted A* with a weight of 1 is A* through the priority queue, so it must find solutions as short
+	// as the arena search does, whatever the tie-break
+	for seed := int64(1); seed <= 3; seed++ {
+		s := scrambled(5, seed)
+		_, want, ok := Search(s, SearchOptions{Heuristic: H})
+		if !ok {
+			t.Fatalf("seed %d: A* found no solution", seed)
+		}
+		for _, tie := range []TieBreak{PreferDeeper, FIFO, LIFO} {
+			node, got, ok := Search(s, SearchOptions{Strategy: WeightedAStar, Weight: 1, TieBreak: tie, Heuristic: H})
+			if !ok {
+				t.Errorf("seed %d, %s: no solution", seed, tie)
+				continue
+			}
+			checkSolves(t, s, Path(node))
+			if got.Depth != want.Depth {
+				t.Errorf("seed %d, %s: %d moves, A* found %d", seed, tie, got.Depth, want.Depth)
+			}
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// This file contains the search strategies that can be used to solve a State.
+// Every strategy shares the Node type, the heuristic and the Stats reported
+// back, so the strategies can be compared against each other on the same
+// scrambles.
+
+package solver
+
+import (
+	"fmt"
+	"megaminx/puzzle"
+	"sort"
+	"time"
+)
+
+// Strategy selects how the frontier of a search is ordered
+type Strategy int
+
+const (
+	AStar           Strategy = iota // f = g + h
+	WeightedAStar                   // f = g + w*h
+	GreedyBestFirst                 // f = h, ties going to the smaller g before TieBreak
+	BeamSearch                      // breadth-first, keeping only the best B nodes of each depth
+	UniformCost                     // f = g, which is breadth-first search since every move costs 1
+	IDAStar                         // depth-first with an increasing bound on g + h, using almost no memory
+)
+
+var strategyNames = map[Strategy]string{
+	AStar:           "A*",
+	WeightedAStar:   "weighted A*",
+	GreedyBestFirst: "greedy best-first",
+	BeamSearch:      "beam",
+	UniformCost:     "uniform-cost",
+	IDAStar:         "IDA*",
+}
+
+// String returns the name of strategy st
+func (st Strategy) String() string {
+	if name, ok := strategyNames[st]; ok {
+		return name
+	}
+	return fmt.Sprintf("Strategy(%d)", int(st))
+}
+
+// Heuristic estimates the number of moves needed to solve a state
+type Heuristic func(s puzzle.State) int
+
+// MoveSet selects the turns a search may make. Pattern database tables count counter-clockwise turns, so
+// with the other move sets they overestimate and A* may find solutions longer than the shortest
+type MoveSet int
+
+const (
+	CounterClockwise MoveSet = iota // a fifth of a turn counter-clockwise
+	FifthTurns                      // a fifth of a turn either way
+	FaceTurns                       // one or two fifths of a turn either way
+)
+
+var moveSetNames = map[MoveSet]string{
+	CounterClockwise: "ccw",
+	FifthTurns:       "fifths",
+	FaceTurns:        "face",
+}
+
+// String returns the name of move set ms
+func (ms MoveSet) String() string {
+	if name, ok := moveSetNames[ms]; ok {
+		return name
+	}
+	return fmt.Sprintf("MoveSet(%d)", int(ms))
+}
+
+// ParseMoveSet returns the move set called name by String
+func ParseMoveSet(name string) (MoveSet, error) {
+	for ms, n := range moveSetNames {
+		if n == name {
+			return ms, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown move set %q", name)
+}
+
+// moves lists the turns of ms, by face. Searches store a turn as its index in this list
+func (ms MoveSet) moves() []puzzle.Move {
+	var turns []int
+	switch ms {
+	case FifthTurns:
+		turns = []int{-1, 1}
+	case FaceTurns:
+		turns = []int{-1, 1, -2, 2}
+	default:
+		turns = []int{-1}
+	}
+	var res []puzzle.Move
+	for face := 0; face < 12; face++ {
+		for _, t := range turns {
+			res = append(res, puzzle.Move{Face: face, Turns: t})
+		}
+	}
+	return res
+}
+
+// SearchOptions configures a call to Search. The zero value is plain A* using the default heuristic
+type SearchOptions struct {
+	Strategy  Strategy
+	Weight    float64   // weight applied to h by WeightedAStar, defaults to 2
+	BeamWidth int       // number of nodes kept per depth by BeamSearch, defaults to 100
+	Heuristic Heuristic // heuristic used by every strategy, defaults to the loaded tables, or H without them
+	MaxNodes  int       // gives up after expanding this many nodes, 0 means no limit
+	TieBreak  TieBreak  // order of frontier nodes with equal priority, defaults to PreferDeeper
+	Moves     MoveSet   // turns searched, defaults to CounterClockwise
+
+	// A*, uniform-cost, greedy best-first and IDA* can save their progress to a file and resume from it
+	// with ResumeSearch
+	Checkpoint      string          // file checkpoints are written to, "" disables checkpointing
+	CheckpointEvery time.Duration   // time between checkpoints, defaults to a minute
+	Stop            <-chan struct{} // closing it stops any search, after writing a final checkpoint if it can
+
+	// Progress, if not nil, is called from the searching goroutine with the stats so far every 1024 nodes
+	Progress func(Stats)
+}
+
+// Stats reports how much work a search did
+type Stats struct {
+	Strategy    Strategy
+	Expanded    int           // nodes removed from the frontier and expanded
+	Generated   int           // child nodes created
+	MaxFrontier int           // largest size the frontier reached
+	Frontier    int           // size of the frontier when the search stopped
+	Depth       int           // length of the solution found, -1 if none was found
+	Elapsed     time.Duration // wall clock time spent searching, including before the search was resumed
+	Stopped     bool          // the search was stopped through SearchOptions.Stop
+	Err         error         // error writing a checkpoint, the search itself carries on
+}
+
+// withDefaults fills in the zero fields of o
+func (o SearchOptions) withDefaults() SearchOptions {
+	if o.Weight == 0 {
+		o.Weight = 2
+	}
+	if o.BeamWidth <= 0 {
+		o.BeamWidth = 100
+	}
+	if o.Heuristic == nil {
+		o.Heuristic = defaultHeuristic()
+	}
+	if o.CheckpointEvery <= 0 {
+		o.CheckpointEvery = time.Minute
+	}
+	return o
+}
+
+// priority returns the value the frontier is ordered by for node n
+func (o SearchOptions) priority(n Node) float64 {
+	switch o.Strategy {
+	case WeightedAStar:
+		return float64(n.g) + o.Weight*float64(n.h)
+	case GreedyBestFirst:
+		return float64(n.h)
+	case UniformCost:
+		return float64(n.g)
+	default:
+		return float64(n.g + n.h)
+	}
+}
+
+// Search solves s using the strategy in opts. The returned bool is false if no solution was found
+func Search(s puzzle.State, opts SearchOptions) (Node, Stats, bool) {
+	return search(s, opts, nil)
+}
+
+// search runs the search, continuing from resume if it is not nil
+func search(s puzzle.State, opts SearchOptions, resume *checkpoint) (Node, Stats, bool) {
+	heuristic := heuristicName(opts)
+	opts = opts.withDefaults()
+	stats := Stats{Strategy: opts.Strategy, Depth: -1}
+	if resume != nil {
+		stats.Expanded, stats.Generated, stats.MaxFrontier = resume.Expanded, resume.Generated, resume.MaxFrontier
+		stats.Elapsed = resume.Elapsed
+	}
+	cp := newCheckpointer(s, opts, heuristic, &stats)
+
+	var node Node
+	var ok bool
+	switch opts.Strategy {
+	case BeamSearch:
+		node, ok = beam(s, opts, &stats, cp)
+	case WeightedAStar: // the only strategy whose priority is not an integer
+		node, ok = bestFirst(s, opts, &stats, cp)
+	case IDAStar:
+		node, ok = idaStar(s, opts, &stats, cp, resume)
+	default:
+		node, ok = arenaSearch(s, opts, &stats, cp, resume)
+	}
+	if opts.Checkpoint != "" && (opts.Strategy == BeamSearch || opts.Strategy == WeightedAStar) {
+		stats.Err = fmt.Errorf("%s search can not be checkpointed", opts.Strategy)
+	}
+
+	if ok {
+		stats.Depth = node.g
+	}
+	stats.Elapsed = cp.elapsed()
+	return node, stats, ok
+}
+
+// bestFirst searches s with a priority queue ordered by opts.priority. It stops when cp says so but never
+// writes checkpoints
+func bestFirst(s puzzle.State, opts SearchOptions, stats *Stats, cp *checkpointer) (Node, bool) {
+	start := Node{s: &s, h: opts.Heuristic(s)}
+	start.f = opts.priority(start)
+	pq := NewPriorityQueue[Node](opts.TieBreak)
+	moves := opts.Moves.moves()
+	reached := make(map[string]int)
+	open := make(map[string]*Item[Node]) // frontier nodes, so a shorter path to one lowers its key
+	reached[s.String()] = 0
+	open[s.String()] = pq.Push(start, start.f, start.g)
+
+	for pq.Len() > 0 { // while the frontier is non-empty
+		if pq.Len() > stats.MaxFrontier {
+			stats.MaxFrontier = pq.Len()
+		}
+		top := pq.Pop()   // extract min from frontier
+		if top.solved() { // if goal state reached, we're done
+			stats.Frontier = pq.Len()
+			return top, true
+		}
+		delete(open, top.s.String())
+		if opts.MaxNodes > 0 && stats.Expanded >= opts.MaxNodes {
+			break
+		}
+		if _, stop := cp.poll(); stop {
+			stats.Stopped = true
+			break
+		}
+		stats.Expanded++
+		for _, child := range children(top, opts.Heuristic, moves) { // for each child node
+			stats.Generated++
+			c := child.s.String()                                 // get string encoding for lookup into reached nodes map
+			if _, ok := reached[c]; !ok || child.g < reached[c] { // if child hasn't been reached, or shorter path to child found
+				reached[c] = child.g // update cost for child node
+				child.f = opts.priority(child)
+				if it, ok := open[c]; ok && pq.DecreaseKey(it, child, child.f, child.g) {
+					continue // child was already in the frontier, now with the shorter path
+				}
+				open[c] = pq.Push(child, child.f, child.g) // insert child into frontier
+			}
+		}
+	}
+	stats.Frontier = pq.Len()
+	return Node{}, false
+}
+
+// beam searches s one depth at a time, keeping only the opts.BeamWidth nodes with the smallest h at each depth.
+// Like bestFirst, it stops when cp says so
+func beam(s puzzle.State, opts SearchOptions, stats *Stats, cp *checkpointer) (Node, bool) {
+	level := []Node{{s: &s, h: opts.Heuristic(s)}}
+	moves := opts.Moves.moves()
+	reached := make(map[string]bool)
+	reached[s.String()] = true
+
+	for len(level) > 0 {
+		if len(level) > stats.MaxFrontier {
+			stats.MaxFrontier = len(level)
+		}
+		for _, n := range level {
+			if n.solved() {
+				stats.Frontier = len(level)
+				return n, true
+			}
+		}
+
+		var next []Node
+		for _, n := range level {
+			if opts.MaxNodes > 0 && stats.Expanded >= opts.MaxNodes {
+				stats.Frontier = len(level)
+				return Node{}, false
+			}
+			if _, stop := cp.poll(); stop {
+				stats.Stopped = true
+				stats.Frontier = len(level)
+				return Node{}, false
+			}
+			stats.Expanded++
+			for _, child := range children(n, opts.Heuristic, moves) {
+				stats.Generated++
+				c := child.s.String()
+				if !reached[c] {
+					reached[c] = true
+					next = append(next, child)
+				}
+			}
+		}
+
+		// every node in next has the same g, so ordering by h is ordering by g + h
+		sort.SliceStable(next, func(i, j int) bool {
+			return next[i].h < next[j].h
+		})
+		if len(next) > opts.BeamWidth {
+			next = next[:opts.BeamWidth]
+		}
+		level = next
+	}
+	stats.Frontier = 0
+	return Node{}, false
+}
@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"math/rand"
+	"megaminx/puzzle"
+	"testing"
+)
+
+// scrambled returns the state reached by a seeded scramble of moves turns
+func scrambled(moves int, seed int64) puzzle.State {
+	s := puzzle.NewState()
+	s.Scramble(moves, rand.New(rand.NewSource(seed)))
+	return s
+}
+
+// checkSolves fails t unless moves solve s
+func checkSolves(t *testing.T, s puzzle.State, moves puzzle.Sequence) {
+	t.Helper()
+	moves.Apply(&s)
+	if s != puzzle.NewState() {
+		t.Errorf("%s does not solve the position", moves)
+	}
+}
+
+func TestSearchStrategies(t *testing.T) {
+	for _, strategy := range []Strategy{AStar, WeightedAStar, GreedyBestFirst, BeamSearch, UniformCost, IDAStar} {
+		for seed := int64(1); seed <= 3; seed++ {
+			s := scrambled(4, seed)
+			node, stats, ok := Search(s, SearchOptions{Strategy: strategy, Heuristic: H})
+			if !ok {
+				t.Errorf("%s, seed %d: no solution after expanding %d nodes", strategy, seed, stats.Expanded)
+				continue
+			}
+			path := Path(node)
+			checkSolves(t, s, path)
+			if stats.Depth != len(path) {
+				t.Errorf("%s, seed %d: Depth = %d for a path of %d moves", strategy, seed, stats.Depth, len(path))
+			}
+			if strategy == AStar || strategy == UniformCost || strategy == IDAStar {
+				if len(path) > 4 {
+					t.Errorf("%s, seed %d: %d moves for a 4 turn scramble", strategy, seed, len(path))
+				}
+			}
+		}
+	}
+}
+
+func TestSearchGoalIsSolvedState(t *testing.T) {
+	// a heuristic of 0 everywhere must not end the search before the puzzle is solved
+	zero := func(puzzle.State) int { return 0 }
+	s := scrambled(3, 7)
+	node, _, ok := Search(s, SearchOptions{Strategy: GreedyBestFirst, Heuristic: zero, MaxNodes: 100000})
+	if !ok {
+		t.Fatal("no solution found")
+	}
+	checkSolves(t, s, Path(node))
+}
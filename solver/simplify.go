@@ -0,0 +1,62 @@
+// This file contains a simplifier for move sequences. Solutions put together
+// from search paths or from the stages of a solver often turn the same face
+// several times in a row, or undo a move of one stage at the start of the next.
+
+package solver
+
+import "megaminx/puzzle"
+
+// Simplify returns a sequence with the same effect as seq and no more moves. Turns of the same face are
+// merged modulo 5 and dropped when they cancel. Turns of faces that do not share an edge commute, so a
+// move is merged with the last turn of its face as long as only turns of such faces lie in between
+func Simplify(seq puzzle.Sequence) puzzle.Sequence {
+	res := simplifyPass(seq)
+	for {
+		// dropping a move can let the moves on either side of it meet, so repeat until nothing changes
+		next := simplifyPass(res)
+		if len(next) == len(res) {
+			return next
+		}
+		res = next
+	}
+}
+
+// simplifyPass merges every move of seq into the result built so far
+func simplifyPass(seq puzzle.Sequence) puzzle.Sequence {
+	var res puzzle.Sequence
+	for _, mv := range seq {
+		mv = mv.Normalize()
+		if mv.Turns == 0 {
+			continue
+		}
+		res = pushMove(res, mv)
+	}
+	return res
+}
+
+// pushMove appends mv to res, merging it with an earlier turn of the same face it commutes back to
+func pushMove(res puzzle.Sequence, mv puzzle.Move) puzzle.Sequence {
+	for i := len(res) - 1; i >= 0; i-- {
+		if res[i].Face == mv.Face {
+			merged := puzzle.Move{Face: mv.Face, Turns: res[i].Turns + mv.Turns}.Normalize()
+			if merged.Turns == 0 {
+				return append(res[:i], res[i+1:]...)
+			}
+			res[i] = merged
+			return res
+		}
+		if adjacent(res[i].Face, mv.Face) {
+			break // mv can not move past res[i]
+		}
+	}
+	return append(res, mv)
+}
+
+// JoinStages returns the moves of every stage in order
+func JoinStages(stages []Stage) puzzle.Sequence {
+	var res puzzle.Sequence
+	for _, st := range stages {
+		res = append(res, st.Moves...)
+	}
+	return res
+}
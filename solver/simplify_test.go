@@ -0,0 +1,52 @@
+package solver
+
+import (
+	"math/rand"
+	"megaminx/puzzle"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		in   puzzle.Sequence
+		want int
+	}{
+		{puzzle.Sequence{{Face: 0, Turns: 1}, {Face: 0, Turns: 1}}, 1},
+		{puzzle.Sequence{{Face: 0, Turns: 2}, {Face: 0, Turns: 3}}, 0},
+		{puzzle.Sequence{{Face: 0, Turns: 1}, {Face: 1, Turns: 1}, {Face: 1, Turns: -1}, {Face: 0, Turns: -1}}, 0},
+		{puzzle.Sequence{{Face: 0, Turns: 1}, {Face: 1, Turns: 1}, {Face: 0, Turns: 1}}, 3},
+	}
+	for _, tt := range tests {
+		got := Simplify(tt.in)
+		if len(got) != tt.want {
+			t.Errorf("Simplify(%s) = %s, want %d moves", tt.in, got, tt.want)
+		}
+		a, b := puzzle.NewState(), puzzle.NewState()
+		tt.in.Apply(&a)
+		got.Apply(&b)
+		if a != b {
+			t.Errorf("Simplify(%s) = %s, which has another effect", tt.in, got)
+		}
+	}
+}
+
+func TestSimplifyKeepsEffect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var seq puzzle.Sequence
+		for j := 0; j < 30; j++ {
+			// few faces, so turns of the same face often meet
+			seq = append(seq, puzzle.Move{Face: rng.Intn(3), Turns: rng.Intn(5) - 2})
+		}
+		got := Simplify(seq)
+		a, b := puzzle.NewState(), puzzle.NewState()
+		seq.Apply(&a)
+		got.Apply(&b)
+		if a != b {
+			t.Fatalf("Simplify(%s) = %s, which has another effect", seq, got)
+		}
+		if again := Simplify(got); len(again) != len(got) {
+			t.Fatalf("Simplify(%s) = %s, which simplifies further to %s", seq, got, again)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package solver solves the megaminx: best-first searches over the sticker
+// model, sped up by pattern database heuristics and a cache of solutions, and
+// the layer-by-layer and multi-phase methods, which work on the pieces and can
+// solve any scramble.
+package solver
+
+import (
+	"math"
+	"megaminx/puzzle"
+)
+
+// Node represents Nodes on A* search
+type Node struct {
+	prev *Node
+	s    *puzzle.State
+	g    int
+	h    int
+	f    float64     // priority of the node in the frontier, set by the search strategy
+	move puzzle.Move // move that turned prev into this node
+}
+
+// solvedState is the state every search is looking for. A heuristic of 0 does not mean a state is solved,
+// as only the default heuristics promise that
+var solvedState = puzzle.NewState()
+
+// solved reports whether n is the solved state
+func (n Node) solved() bool {
+	return *n.s == solvedState
+}
+
+// State returns the state of n
+func (n Node) State() puzzle.State {
+	return *n.s
+}
+
+// H returns the heuristic value of a given state
+func H(s puzzle.State) int {
+	wrong := 0 // count of stickers on the wrong face
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 10; j++ {
+			if s[i][j] != byte(i) {
+				wrong++
+			}
+		}
+	}
+	return int(math.Ceil(float64(wrong) / 15.0)) // ceil(wrong / 15)
+}
+
+// Child returns all children of Node n, using h to evaluate each child
+// Note: only children generated by rotating the puzzle counter-clockwise are considered
+func Child(n Node, h Heuristic) []Node {
+	return children(n, h, CounterClockwise.moves())
+}
+
+// children returns the children of n reached by each of moves
+func children(n Node, h Heuristic, moves []puzzle.Move) []Node {
+	var res []Node
+	for _, mv := range moves {
+		s := puzzle.CopyState(*(n.s))
+		mv.Apply(&s)
+		res = append(res, Node{prev: &n, s: &s, g: n.g + 1, h: h(s), move: mv})
+	}
+	return res
+}
+
+// Path returns the moves leading from the start of the search to n
+func Path(n Node) puzzle.Sequence {
+	res := make(puzzle.Sequence, n.g)
+	for ; n.prev != nil; n = *n.prev {
+		res[n.g-1] = n.move
+	}
+	return res
+}
+
+// Solve is an implementation of A*, returns the size of the frontier when the solved state is reached
+// States solved before, or rotations of them, are looked up in DefaultCache and reported with a frontier of 0
+func Solve(s puzzle.State) (int, Node) {
+	node, stats, ok := SolveWith(s, nil, nil)
+	if !ok {
+		return -1, Node{} // return -1 if unsolvable, shouldn't happen with any start state generated by Randomize
+	}
+	return stats.Frontier, node
+}
+
+// SolveWith is Solve for searches run in the background: closing stop ends the search and progress, if
+// not nil, is called from the searching goroutine with the stats so far. Cached solutions come with zero
+// stats, and the bool is false if the search was stopped
+func SolveWith(s puzzle.State, stop <-chan struct{}, progress func(Stats)) (Node, Stats, bool) {
+	return SearchCached(s, SearchOptions{Strategy: AStar, Stop: stop, Progress: progress})
+}
+
+// SearchCached is Search looking s up in DefaultCache first, cached solutions coming with zero stats.
+// Only shortest solutions are stored in it, so only those of searches for which optimal holds
+func SearchCached(s puzzle.State, opts SearchOptions) (Node, Stats, bool) {
+	if _, moves, ok := DefaultCache.Get(s, opts.Moves); ok {
+		return pathNode(s, moves, opts.withDefaults()), Stats{Strategy: opts.Strategy, Depth: len(moves)}, true
+	}
+	node, stats, ok := Search(s, opts)
+	if ok && opts.optimal() {
+		DefaultCache.Put(s, opts.Moves, node.g, Path(node))
+	}
+	return node, stats, ok
+}
+
+// optimal reports whether a search with o finds shortest solutions: uniform-cost, or A* and IDA* with a
+// heuristic that never overestimates in moves of o.Moves. H does not for any move set, as a turn moves
+// 15 stickers to other faces, and the tables only count counter-clockwise turns. Nothing is known of other
+// heuristics
+func (o SearchOptions) optimal() bool {
+	switch o.Strategy {
+	case UniformCost:
+		return true
+	case AStar, IDAStar:
+		switch heuristicName(o) {
+		case "H":
+			return true
+		case "tables":
+			return o.Moves == CounterClockwise
+		}
+	}
+	return false
+}
@@ -0,0 +1,416 @@
+// This file contains pattern databases, pruning tables that hold the exact
+// number of counter-clockwise turns needed to solve a small group of pieces
+// from every placement of that group. The largest of them is an admissible and
+// consistent heuristic for A*, much stronger than H.
+//
+// The tables take a while to build, so they can be written to a file once per
+// machine and memory mapped on later runs. The file starts with a fixed size
+// header, followed by one descriptor per table and then the tables themselves:
+//
+//	header      magic "MEGAPDB\x00", version, puzzle, metric, table count (uint16 each),
+//	            payload length (uint64), CRC-32 of everything after the header (uint32), 4 reserved bytes
+//	descriptor  kind (corners or edges), number of pieces, up to 6 piece positions (bytes),
+//	            offset of the table from the start of the file and its length (uint32 each)
+//
+// All integers are little endian.
+
+package solver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"megaminx/puzzle"
+	"os"
+	"path/filepath"
+)
+
+const (
+	tableMagic     = "MEGAPDB\x00"
+	tableVersion   = 1
+	puzzleMegaminx = 1 // the only puzzle so far
+	metricCCW      = 1 // one counter-clockwise fifth turn is one move, the moves Child generates
+
+	headerSize     = 32
+	descriptorSize = 16
+	maxGroup       = 6 // most pieces a descriptor has room for
+	unsolvable     = 0xff
+)
+
+// patternTable holds the distance to solve the pieces belonging in positions for each placement of them.
+// The index of a placement is the position of each piece in mixed radix, followed by their orientations
+type patternTable struct {
+	edge      bool
+	positions []int
+	dist      []byte
+}
+
+// Tables is a set of pattern databases. Its Heuristic returns the largest distance found in any of them
+type Tables struct {
+	tables []patternTable
+	close  func() error // unmaps the file the tables were loaded from
+}
+
+// tables is the set of pattern databases in use, nil if none were built or loaded
+var tables *Tables
+
+// UseTables makes t the heuristic searches use unless told otherwise
+func UseTables(t *Tables) {
+	tables = t
+}
+
+// CurrentTables returns the tables set by UseTables, nil if there are none
+func CurrentTables() *Tables {
+	return tables
+}
+
+// defaultHeuristic returns the heuristic used when SearchOptions does not name one
+func defaultHeuristic() Heuristic {
+	if tables != nil {
+		return tables.Heuristic
+	}
+	return H
+}
+
+// DefaultTablesPath returns where the tables of this machine are kept, in the user's cache directory
+func DefaultTablesPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "megaminx", "megaminx.pdb")
+}
+
+// size returns the number of placements of the pieces of pt
+func (pt *patternTable) size() int {
+	n, twists := puzzle.NumCorners, 3
+	if pt.edge {
+		n, twists = puzzle.NumEdges, 2
+	}
+	size := 1
+	for range pt.positions {
+		size *= n * twists
+	}
+	return size
+}
+
+// index returns the index of the placement where piece i of pt is in pos[i] with orientation ori[i]
+func (pt *patternTable) index(pos, ori []int) int {
+	n, twists := puzzle.NumCorners, 3
+	if pt.edge {
+		n, twists = puzzle.NumEdges, 2
+	}
+	idx := 0
+	for i := range pt.positions {
+		idx = idx*n + pos[i]
+	}
+	for i := range pt.positions {
+		idx = idx*twists + ori[i]
+	}
+	return idx
+}
+
+// forward turns the piece moves around: a clockwise turn of face takes the piece in position i to
+// cornerDest[face][i], adding cornerTwist[face][i] to its orientation
+var (
+	cornerDest, cornerTwist [12][puzzle.NumCorners]int
+	edgeDest, edgeFlip      [12][puzzle.NumEdges]int
+)
+
+func init() {
+	for face := 0; face < 12; face++ {
+		pm, _ := puzzle.FaceTurn(face)
+		for i := 0; i < puzzle.NumCorners; i++ {
+			cornerDest[face][pm.CP[i]] = i
+			cornerTwist[face][pm.CP[i]] = int(pm.CO[i])
+		}
+		for i := 0; i < puzzle.NumEdges; i++ {
+			edgeDest[face][pm.EP[i]] = i
+			edgeFlip[face][pm.EP[i]] = int(pm.EO[i])
+		}
+	}
+}
+
+// build fills pt with a breadth-first search from the solved placement. A placement one counter-clockwise
+// turn away from a solved one is one clockwise turn of the other way around, so the search turns clockwise
+func (pt *patternTable) build() {
+	twists := 3
+	turn := func(face, pos, ori int) (int, int) {
+		return cornerDest[face][pos], (ori + cornerTwist[face][pos]) % 3
+	}
+	if pt.edge {
+		twists = 2
+		turn = func(face, pos, ori int) (int, int) {
+			return edgeDest[face][pos], ori ^ edgeFlip[face][pos]
+		}
+	}
+	k := len(pt.positions)
+	pt.dist = make([]byte, pt.size())
+	for i := range pt.dist {
+		pt.dist[i] = unsolvable
+	}
+
+	pos, ori := make([]int, k), make([]int, k)
+	decode := func(idx int) {
+		n := puzzle.NumCorners
+		if pt.edge {
+			n = puzzle.NumEdges
+		}
+		for i := k - 1; i >= 0; i-- {
+			ori[i] = idx % twists
+			idx /= twists
+		}
+		for i := k - 1; i >= 0; i-- {
+			pos[i] = idx % n
+			idx /= n
+		}
+	}
+
+	copy(pos, pt.positions)
+	for i := range ori {
+		ori[i] = 0
+	}
+	start := pt.index(pos, ori)
+	pt.dist[start] = 0
+	level := []int{start}
+	for d := byte(1); len(level) > 0; d++ {
+		var next []int
+		for _, idx := range level {
+			for face := 0; face < 12; face++ {
+				decode(idx)
+				for i := range pos {
+					pos[i], ori[i] = turn(face, pos[i], ori[i])
+				}
+				if n := pt.index(pos, ori); pt.dist[n] == unsolvable {
+					pt.dist[n] = d
+					next = append(next, n)
+				}
+			}
+		}
+		level = next
+	}
+}
+
+// BuildTables builds pattern databases for groups of three corners and groups of three edges, covering
+// every piece
+func BuildTables() *Tables {
+	t := &Tables{}
+	for _, kind := range []struct {
+		edge bool
+		n    int
+	}{{false, puzzle.NumCorners}, {true, puzzle.NumEdges}} {
+		for first := 0; first < kind.n; first += 3 {
+			pt := patternTable{edge: kind.edge}
+			for pos := first; pos < first+3 && pos < kind.n; pos++ {
+				pt.positions = append(pt.positions, pos)
+			}
+			pt.build()
+			t.tables = append(t.tables, pt)
+		}
+	}
+	return t
+}
+
+// locate tables map the colors of a corner or edge, read in facelet order, to the piece and its orientation
+var (
+	cornerByColors [12][12][12]int8
+	edgeByColors   [12][12]int8
+)
+
+// cornerFacelets and edgeFacelets are the stickers of each position, copied once as Heuristic reads them for
+// every state
+var (
+	cornerFacelets = puzzle.CornerFacelets()
+	edgeFacelets   = puzzle.EdgeFacelets()
+)
+
+func init() {
+	for c := range cornerByColors {
+		for d := range cornerByColors[c] {
+			for e := range cornerByColors[c][d] {
+				cornerByColors[c][d][e] = -1
+			}
+			edgeByColors[c][d] = -1
+		}
+	}
+	for piece, fs := range cornerFacelets {
+		for ori := 0; ori < 3; ori++ {
+			var colors [3]int
+			for j := range fs {
+				colors[(j+ori)%3] = fs[j].Face
+			}
+			cornerByColors[colors[0]][colors[1]][colors[2]] = int8(piece*3 + ori)
+		}
+	}
+	for piece, fs := range edgeFacelets {
+		edgeByColors[fs[0].Face][fs[1].Face] = int8(piece * 2)
+		edgeByColors[fs[1].Face][fs[0].Face] = int8(piece*2 + 1)
+	}
+}
+
+// Heuristic returns the largest number of moves any table of t needs for s. It falls back to H for states
+// with pieces no puzzle has
+func (t *Tables) Heuristic(s puzzle.State) int {
+	var cornerPos, cornerOri [puzzle.NumCorners]int
+	var edgePos, edgeOri [puzzle.NumEdges]int
+	for i, fs := range cornerFacelets {
+		v := cornerByColors[s[fs[0].Face][fs[0].Tile]%12][s[fs[1].Face][fs[1].Tile]%12][s[fs[2].Face][fs[2].Tile]%12]
+		if v < 0 {
+			return H(s)
+		}
+		cornerPos[v/3], cornerOri[v/3] = i, int(v%3)
+	}
+	for i, fs := range edgeFacelets {
+		v := edgeByColors[s[fs[0].Face][fs[0].Tile]%12][s[fs[1].Face][fs[1].Tile]%12]
+		if v < 0 {
+			return H(s)
+		}
+		edgePos[v/2], edgeOri[v/2] = i, int(v%2)
+	}
+
+	best := 0
+	var pos, ori [maxGroup]int
+	for i := range t.tables {
+		pt := &t.tables[i]
+		for j, piece := range pt.positions {
+			if pt.edge {
+				pos[j], ori[j] = edgePos[piece], edgeOri[piece]
+			} else {
+				pos[j], ori[j] = cornerPos[piece], cornerOri[piece]
+			}
+		}
+		if d := int(pt.dist[pt.index(pos[:len(pt.positions)], ori[:len(pt.positions)])]); d != unsolvable && d > best {
+			best = d
+		}
+	}
+	return best
+}
+
+// WriteFile writes t to path in the table file format, creating its directory if needed
+func (t *Tables) WriteFile(path string) error {
+	var desc, payload bytes.Buffer
+	offset := headerSize + descriptorSize*len(t.tables)
+	for _, pt := range t.tables {
+		var d [descriptorSize]byte
+		if pt.edge {
+			d[0] = 1
+		}
+		d[1] = byte(len(pt.positions))
+		for i, pos := range pt.positions {
+			d[2+i] = byte(pos)
+		}
+		binary.LittleEndian.PutUint32(d[8:], uint32(offset+payload.Len()))
+		binary.LittleEndian.PutUint32(d[12:], uint32(len(pt.dist)))
+		desc.Write(d[:])
+		payload.Write(pt.dist)
+	}
+
+	body := append(desc.Bytes(), payload.Bytes()...)
+	var h [headerSize]byte
+	copy(h[:], tableMagic)
+	binary.LittleEndian.PutUint16(h[8:], tableVersion)
+	binary.LittleEndian.PutUint16(h[10:], puzzleMegaminx)
+	binary.LittleEndian.PutUint16(h[12:], metricCCW)
+	binary.LittleEndian.PutUint16(h[14:], uint16(len(t.tables)))
+	binary.LittleEndian.PutUint64(h[16:], uint64(len(body)))
+	binary.LittleEndian.PutUint32(h[24:], crc32.ChecksumIEEE(body))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// write next to the destination and rename, so a reader never maps a half written file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(h[:], body...), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadTables memory maps the table file at path and checks its header and checksum. The tables refer to
+// the mapped file, which stays mapped until Close is called
+func LoadTables(path string) (*Tables, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTables(data)
+	if err != nil {
+		unmap()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	t.close = unmap
+	return t, nil
+}
+
+// parseTables reads the tables in data, which hold slices of data
+func parseTables(data []byte) (*Tables, error) {
+	if len(data) < headerSize || string(data[:8]) != tableMagic {
+		return nil, errors.New("not a pruning table file")
+	}
+	if v := binary.LittleEndian.Uint16(data[8:]); v != tableVersion {
+		return nil, fmt.Errorf("table file version %d, expected %d", v, tableVersion)
+	}
+	if p := binary.LittleEndian.Uint16(data[10:]); p != puzzleMegaminx {
+		return nil, fmt.Errorf("tables are for puzzle %d, expected %d", p, puzzleMegaminx)
+	}
+	if m := binary.LittleEndian.Uint16(data[12:]); m != metricCCW {
+		return nil, fmt.Errorf("tables use metric %d, expected %d", m, metricCCW)
+	}
+	count := int(binary.LittleEndian.Uint16(data[14:]))
+	body := data[headerSize:]
+	if binary.LittleEndian.Uint64(data[16:]) != uint64(len(body)) {
+		return nil, errors.New("table file is truncated")
+	}
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[24:]) {
+		return nil, errors.New("table file checksum does not match")
+	}
+	if len(body) < count*descriptorSize {
+		return nil, errors.New("table file is truncated")
+	}
+
+	// the checksum only shows the file was written whole, so every descriptor is checked before the
+	// heuristic indexes anything with it
+	t := &Tables{}
+	for i := 0; i < count; i++ {
+		d := body[i*descriptorSize:]
+		if d[0] > 1 {
+			return nil, fmt.Errorf("table %d has unknown kind %d", i, d[0])
+		}
+		pt := patternTable{edge: d[0] == 1}
+		pieces := puzzle.NumCorners
+		if pt.edge {
+			pieces = puzzle.NumEdges
+		}
+		n := int(d[1])
+		if n == 0 || n > maxGroup {
+			return nil, fmt.Errorf("table %d has %d pieces", i, n)
+		}
+		var seen [puzzle.NumEdges]bool
+		for j := 0; j < n; j++ {
+			pos := int(d[2+j])
+			if pos >= pieces || seen[pos] {
+				return nil, fmt.Errorf("table %d has piece position %d twice or out of range", i, pos)
+			}
+			seen[pos] = true
+			pt.positions = append(pt.positions, pos)
+		}
+		offset := int(binary.LittleEndian.Uint32(d[8:]))
+		length := int(binary.LittleEndian.Uint32(d[12:]))
+		if length != pt.size() || offset < headerSize+count*descriptorSize || offset+length > len(data) {
+			return nil, fmt.Errorf("table %d has the wrong size", i)
+		}
+		pt.dist = data[offset : offset+length]
+		t.tables = append(t.tables, pt)
+	}
+	return t, nil
+}
+
+// Close releases the file t was loaded from. t must not be used afterwards
+func (t *Tables) Close() error {
+	if t.close == nil {
+		return nil
+	}
+	return t.close()
+}
@@ -0,0 +1,57 @@
+package solver
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tableFile returns the file of a table of three corners, with change applied to its only descriptor and
+// the checksum made right again
+func tableFile(t *testing.T, change func(d []byte)) []byte {
+	t.Helper()
+	pt := patternTable{positions: []int{0, 1, 2}}
+	pt.build()
+	path := filepath.Join(t.TempDir(), "tables")
+	if err := (&Tables{tables: []patternTable{pt}}).WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(data[headerSize : headerSize+descriptorSize])
+	binary.LittleEndian.PutUint32(data[24:], crc32.ChecksumIEEE(data[headerSize:]))
+	return data
+}
+
+func TestParseTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(d []byte)
+		err    string // part of the error expected, "" for none
+	}{
+		{"unchanged", func(d []byte) {}, ""},
+		{"unknown kind", func(d []byte) { d[0] = 7 }, "unknown kind"},
+		{"no pieces", func(d []byte) { d[1] = 0 }, "0 pieces"},
+		{"too many pieces", func(d []byte) { d[1] = maxGroup + 1 }, "7 pieces"},
+		{"corner out of range", func(d []byte) { d[3] = 25 }, "out of range"},
+		{"piece twice", func(d []byte) { d[3] = d[2] }, "twice"},
+		{"edges of a corner table", func(d []byte) { d[0] = 1 }, ""}, // three edges take as many bytes
+		{"wrong length", func(d []byte) { binary.LittleEndian.PutUint32(d[12:], 100) }, "wrong size"},
+		{"offset in the header", func(d []byte) { binary.LittleEndian.PutUint32(d[8:], 4) }, "wrong size"},
+		{"offset past the end", func(d []byte) { binary.LittleEndian.PutUint32(d[8:], 1<<31) }, "wrong size"},
+	}
+	for _, tt := range tests {
+		_, err := parseTables(tableFile(t, tt.change))
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: parseTables() = %v, want nil", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: parseTables() = %v, want an error saying %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !headless
+
+package main
+
+import (
+	"flag"
+	"megaminx/gui"
+	"time"
+)
+
+var (
+	turnTime = flag.Duration("turn-time", 300*time.Millisecond, "time a face turn takes to animate in the window, 0 turns instantly")
+	keysPath = flag.String("keys", "", "JSON file binding faces to keys in the window, such as {\"U\": \"S\"}")
+)
+
+// runGUI opens the puzzle window and returns once it is closed
+func runGUI() error {
+	g := gui.NewGame()
+	g.TurnTime = *turnTime
+	if *keysPath != "" {
+		keys, err := gui.LoadBindings(*keysPath)
+		if err != nil {
+			return err
+		}
+		g.Keys = keys
+	}
+	return gui.Run(g)
+}